@@ -37,12 +37,30 @@ type MethodSpec struct {
 	HttpMethods          []HttpMethod
 	UrlQueries           UrlQueries
 	FormParams           FormParams
+	Constraints          Constraints
 	ContentTypes         []string
 	RequestBody          ObjectFactory
 	ResponseBody         ObjectFactory
 	CallbackEvent        EventKey
 	CallbackBodyTemplate string
 	AuthScope            string
+	AnyOfScopes          []string
+	AllOfScopes          []string
+	Authorize            Authorize
+}
+
+// Whether the method requires auth: the token must have the auth scope or any of the
+// any-of scopes, and all of the all-of scopes.
+func (this MethodSpec) RequiresAuth() bool {
+	return this.AuthScope != "" || len(this.AnyOfScopes) > 0 || len(this.AllOfScopes) > 0
+}
+
+// The scopes of which the token must have any, including the auth scope.
+func (this MethodSpec) AnyScopes() []string {
+	if this.AuthScope == "" {
+		return this.AnyOfScopes
+	}
+	return append([]string{this.AuthScope}, this.AnyOfScopes...)
 }
 
 type ServiceMethods map[ServiceMethod]MethodSpec
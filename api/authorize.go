@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrForbidden = errors.New("error-forbidden")
+)
+
+// The claims of the token of the request, as given by auth.Context.  The context has no
+// claims when auth is off.
+type AuthContext interface {
+	HasKey(key string) bool
+	GetString(key string) string
+	Get(key string) interface{}
+	GetStringForService(service, key string) string
+}
+
+// Authorizes the request for the resource of an authenticated method, given the claims of the
+// token and the route variables.  Runs after the scopes are checked and before the handler.
+// Returns nil to allow, or the error to deny with, rendered as forbidden unless it is an
+// *Error with its own status.
+type Authorize func(ctx AuthContext, vars map[string]string) error
+
+// The claim must equal the route variable, e.g. the account id of the token and {id}.  The
+// requests without the claim or the variable are denied.
+func ClaimEqualsVar(claim, name string) Authorize {
+	return func(ctx AuthContext, vars map[string]string) error {
+		return check_claim(ctx.GetString(claim), vars[name], false)
+	}
+}
+
+// The claim of the service must equal the route variable.
+func ServiceClaimEqualsVar(service, claim, name string) Authorize {
+	return func(ctx AuthContext, vars map[string]string) error {
+		return check_claim(ctx.GetStringForService(service, claim), vars[name], false)
+	}
+}
+
+// The claim, a comma separated list, must contain the route variable, e.g. the ids of the
+// accounts of the token.
+func ClaimContainsVar(claim, name string) Authorize {
+	return func(ctx AuthContext, vars map[string]string) error {
+		return check_claim(ctx.GetString(claim), vars[name], true)
+	}
+}
+
+func check_claim(claim, value string, list bool) error {
+	if claim == "" || value == "" {
+		return ErrForbidden
+	}
+	if !list {
+		if claim == value {
+			return nil
+		}
+		return ErrForbidden
+	}
+	for _, v := range strings.Split(claim, ",") {
+		if strings.TrimSpace(v) == value {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// Allows if all the checks allow; the first denial is returned.
+func AuthorizeAll(checks ...Authorize) Authorize {
+	return func(ctx AuthContext, vars map[string]string) error {
+		for _, check := range checks {
+			if err := check(ctx, vars); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Allows if any of the checks allows; the last denial is returned otherwise.
+func AuthorizeAny(checks ...Authorize) Authorize {
+	return func(ctx AuthContext, vars map[string]string) error {
+		err := ErrForbidden
+		for _, check := range checks {
+			if err = check(ctx, vars); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+}
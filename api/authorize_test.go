@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+type claims map[string]string
+
+func (this claims) HasKey(key string) bool {
+	_, has := this[key]
+	return has
+}
+
+func (this claims) GetString(key string) string {
+	return this[key]
+}
+
+func (this claims) Get(key string) interface{} {
+	return this[key]
+}
+
+func (this claims) GetStringForService(service, key string) string {
+	return this[service+"/"+key]
+}
+
+func TestAuthorizeHelpers(t *testing.T) {
+	ctx := claims{"@account": "1", "shorty/@accounts": "1, 2"}
+	vars := map[string]string{"id": "2", "account": "1"}
+
+	assert.Equal(t, nil, ClaimEqualsVar("@account", "account")(ctx, vars))
+	assert.Equal(t, ErrForbidden, ClaimEqualsVar("@account", "id")(ctx, vars))
+	assert.Equal(t, ErrForbidden, ClaimEqualsVar("@user", "missing")(ctx, vars))
+	assert.Equal(t, ErrForbidden, ServiceClaimEqualsVar("shorty", "@accounts", "id")(ctx, vars))
+	assert.Equal(t, nil, ClaimContainsVar("shorty/@accounts", "id")(ctx, vars))
+	assert.Equal(t, ErrForbidden, ClaimContainsVar("shorty/@accounts", "missing")(ctx, vars))
+
+	assert.Equal(t, ErrForbidden, AuthorizeAll(ClaimEqualsVar("@account", "account"), ClaimEqualsVar("@account", "id"))(ctx, vars))
+	assert.Equal(t, nil, AuthorizeAny(ClaimEqualsVar("@account", "id"), ClaimEqualsVar("@account", "account"))(ctx, vars))
+	assert.Equal(t, ErrForbidden, AuthorizeAny()(ctx, vars))
+}
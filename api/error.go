@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	RequestIdHeader = "X-Request-Id"
+)
+
+// The error returned to clients by the rest engine, the auth service and the runtime
+// manager.  Code is the machine readable code like error-missing-input, Message the human
+// readable description, and Fields the details of any invalid inputs.
+type Error struct {
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
+}
+
+// Returns an error with the http status text as the message.
+func NewError(status int, code string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: http.StatusText(status),
+	}
+}
+
+// Converts any error to the error returned to clients.  Validation errors are reported as
+// bad requests with the field details, and other errors take the given status and their
+// string as the code.
+func ToError(err error, status int) *Error {
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case *Error:
+		return err
+	case *ValidationError:
+		e := NewError(http.StatusBadRequest, ErrInvalidInput.Error())
+		e.Fields = err.Fields
+		return e
+	default:
+		return NewError(status, err.Error())
+	}
+}
+
+func (this *Error) Error() string {
+	return this.Code
+}
+
+// The plain text rendering of the error.
+func (this *Error) String() string {
+	lines := []string{this.Code}
+	if this.Message != "" {
+		lines[0] += ": " + this.Message
+	}
+	for _, f := range this.Fields {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	if this.RequestId != "" {
+		lines = append(lines, "request_id: "+this.RequestId)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Writes the error as json.  Packages that cannot negotiate the content type use this.
+func (this *Error) WriteJSON(resp http.ResponseWriter) error {
+	buff, err := json.Marshal(this)
+	if err != nil {
+		return err
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(this.Status)
+	_, err = resp.Write(buff)
+	return err
+}
+
+// Renders the error as json, with the request id of the request.  This has the signature
+// of the error renderer of the auth service.
+func RenderJSONError(resp http.ResponseWriter, req *http.Request, code string, status int) error {
+	e := NewError(status, code)
+	if req != nil {
+		e.RequestId = req.Header.Get(RequestIdHeader)
+	}
+	return e.WriteJSON(resp)
+}
@@ -0,0 +1,515 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OpenApiVersion = "3.1.0"
+
+	// Names of the security schemes derived from the AuthScope of the methods.
+	// A token can be presented either as a bearer token or as a query parameter.
+	OpenApiBearerAuth      = "bearer"
+	OpenApiQueryParamAuth  = "access_token"
+	openapi_default_method = "GET"
+)
+
+var (
+	route_param_regex = regexp.MustCompile(`\{([^}:]+)(?::([^}]+))?\}`)
+	non_word_regex    = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	time_type         = reflect.TypeOf(time.Time{})
+	bytes_type        = reflect.TypeOf([]byte{})
+	error_type        = reflect.TypeOf(Error{})
+)
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiServer struct {
+	Url         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Default              interface{}               `json:"default,omitempty"`
+	Items                *OpenApiSchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenApiSchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenApiSchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+}
+
+type OpenApiParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenApiSchema `json:"schema,omitempty"`
+}
+
+type OpenApiMediaType struct {
+	Schema *OpenApiSchema `json:"schema,omitempty"`
+}
+
+type OpenApiRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenApiMediaType `json:"content"`
+}
+
+type OpenApiResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenApiMediaType `json:"content,omitempty"`
+}
+
+type OpenApiOperation struct {
+	OperationId string                      `json:"operationId"`
+	Description string                      `json:"description,omitempty"`
+	Parameters  []*OpenApiParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenApiResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type OpenApiSecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type OpenApiComponents struct {
+	Schemas         map[string]*OpenApiSchema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*OpenApiSecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// The path item maps the lower case http method to the operation.
+type OpenApiPathItem map[string]*OpenApiOperation
+
+type OpenApiDocument struct {
+	OpenApi    string                     `json:"openapi"`
+	Info       OpenApiInfo                `json:"info"`
+	Servers    []OpenApiServer            `json:"servers,omitempty"`
+	Paths      map[string]OpenApiPathItem `json:"paths"`
+	Components OpenApiComponents          `json:"components"`
+}
+
+// Generates the OpenAPI document describing the service methods.  Request and response
+// bodies are described by reflecting over the objects returned by the factories, so both
+// plain structs and generated protobuf messages are supported.
+func (this ServiceMethods) OpenApi(info OpenApiInfo, servers ...OpenApiServer) *OpenApiDocument {
+	doc := &OpenApiDocument{
+		OpenApi: OpenApiVersion,
+		Info:    info,
+		Servers: servers,
+		Paths:   make(map[string]OpenApiPathItem),
+		Components: OpenApiComponents{
+			Schemas: make(map[string]*OpenApiSchema),
+		},
+	}
+	builder := &schema_builder{
+		schemas: doc.Components.Schemas,
+		types:   make(map[string]reflect.Type),
+	}
+
+	// Sort the keys so that the generated document is deterministic.
+	keys := []int{}
+	for k, _ := range this {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+
+	scopes := []string{}
+	for _, k := range keys {
+		m := this[ServiceMethod(k)]
+		path, path_params := openapi_path(m.UrlRoute)
+		item, has := doc.Paths[path]
+		if !has {
+			item = make(OpenApiPathItem)
+			doc.Paths[path] = item
+		}
+		for _, method := range m.methods() {
+			item[strings.ToLower(string(method))] = builder.operation(m, method, path_params)
+		}
+		scopes = append(scopes, m.AnyScopes()...)
+		scopes = append(scopes, m.AllOfScopes...)
+	}
+
+	if len(scopes) > 0 {
+		desc := fmt.Sprintf("JWT carrying the scopes: %s", strings.Join(unique_strings(scopes), ", "))
+		doc.Components.SecuritySchemes = map[string]*OpenApiSecurityScheme{
+			OpenApiBearerAuth: &OpenApiSecurityScheme{
+				Type:         "http",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+				Description:  desc,
+			},
+			OpenApiQueryParamAuth: &OpenApiSecurityScheme{
+				Type:        "apiKey",
+				In:          "query",
+				Name:        OpenApiQueryParamAuth,
+				Description: desc,
+			},
+		}
+	}
+	return doc
+}
+
+func (this *OpenApiDocument) ToJSON(indent bool) ([]byte, error) {
+	if indent {
+		return json.MarshalIndent(this, "", "  ")
+	} else {
+		return json.Marshal(this)
+	}
+}
+
+// The YAML keeps the keys in the order of the JSON encoding.  JSON being YAML, the JSON
+// is decoded as an ordered yaml.MapSlice and encoded again.
+func (this *OpenApiDocument) ToYAML() ([]byte, error) {
+	buff, err := json.Marshal(this)
+	if err != nil {
+		return nil, err
+	}
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(buff, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+func (this MethodSpec) methods() []HttpMethod {
+	methods := []HttpMethod{}
+	if this.HttpMethod != "" {
+		methods = append(methods, this.HttpMethod)
+	}
+	for _, m := range this.HttpMethods {
+		if m != this.HttpMethod {
+			methods = append(methods, m)
+		}
+	}
+	if len(methods) == 0 {
+		methods = append(methods, HttpMethod(openapi_default_method))
+	}
+	return methods
+}
+
+func (this MethodSpec) content_types() []string {
+	types := []string{}
+	for _, ct := range this.ContentTypes {
+		if ct != "" {
+			types = append(types, ct)
+		}
+	}
+	if len(types) == 0 {
+		types = append(types, "application/json")
+	}
+	return types
+}
+
+// Converts the gorilla mux route template to the OpenAPI path template.  Any regular
+// expression constraint on a route variable becomes the pattern of the path parameter.
+func openapi_path(route string) (string, []*OpenApiParameter) {
+	params := []*OpenApiParameter{}
+	path := route_param_regex.ReplaceAllStringFunc(route, func(match string) string {
+		parts := route_param_regex.FindStringSubmatch(match)
+		schema := &OpenApiSchema{Type: "string"}
+		if parts[2] != "" {
+			schema.Pattern = "^" + parts[2] + "$"
+		}
+		params = append(params, &OpenApiParameter{
+			Name:     parts[1],
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+		return "{" + parts[1] + "}"
+	})
+	return path, params
+}
+
+func operation_id(method HttpMethod, path string) string {
+	id := strings.Trim(non_word_regex.ReplaceAllString(path, "_"), "_")
+	return strings.ToLower(string(method)) + "_" + id
+}
+
+type schema_builder struct {
+	schemas map[string]*OpenApiSchema
+	types   map[string]reflect.Type
+}
+
+func (this *schema_builder) operation(m MethodSpec, method HttpMethod, path_params []*OpenApiParameter) *OpenApiOperation {
+	path, _ := openapi_path(m.UrlRoute)
+	op := &OpenApiOperation{
+		OperationId: operation_id(method, path),
+		Description: m.Doc,
+		Parameters:  append([]*OpenApiParameter{}, path_params...),
+		Responses:   make(map[string]*OpenApiResponse),
+	}
+
+	for _, name := range sorted_keys(m.UrlQueries) {
+		op.Parameters = append(op.Parameters, &OpenApiParameter{
+			Name:   name,
+			In:     "query",
+			Schema: this.default_value_schema(m.UrlQueries[name]),
+		})
+	}
+
+	header_keys := []string{}
+	for k, _ := range m.HttpHeaders {
+		header_keys = append(header_keys, k)
+	}
+	sort.Strings(header_keys)
+	for _, k := range header_keys {
+		op.Parameters = append(op.Parameters, &OpenApiParameter{
+			Name:   m.HttpHeaders[k],
+			In:     "header",
+			Schema: &OpenApiSchema{Type: "string"},
+		})
+	}
+
+	has_body := method == POST || method == PUT || method == PATCH
+	switch {
+	case has_body && m.RequestBody != nil:
+		if schema := this.object_schema(m.RequestBody); schema != nil {
+			op.RequestBody = &OpenApiRequestBody{
+				Required: true,
+				Content:  this.content(m.content_types(), schema),
+			}
+		}
+	case has_body && len(m.FormParams) > 0:
+		schema := &OpenApiSchema{
+			Type:       "object",
+			Properties: make(map[string]*OpenApiSchema),
+		}
+		for k, v := range m.FormParams {
+			schema.Properties[k] = this.default_value_schema(v)
+		}
+		op.RequestBody = &OpenApiRequestBody{
+			Content: this.content([]string{"application/x-www-form-urlencoded", "multipart/form-data"}, schema),
+		}
+	}
+
+	ok := &OpenApiResponse{Description: "OK"}
+	if m.ResponseBody != nil {
+		if schema := this.object_schema(m.ResponseBody); schema != nil {
+			ok.Content = this.content(m.content_types(), schema)
+		}
+	}
+	op.Responses["200"] = ok
+	op.Responses["default"] = &OpenApiResponse{
+		Description: "Error",
+		Content:     this.content([]string{"application/json"}, this.schema_for(error_type)),
+	}
+
+	if m.RequiresAuth() {
+		op.Security = openapi_security(m)
+		op.Responses["401"] = &OpenApiResponse{Description: "Unauthorized"}
+		if m.Authorize != nil {
+			op.Responses["403"] = &OpenApiResponse{Description: "Forbidden"}
+		}
+	}
+	return op
+}
+
+// The alternatives of the security requirements, each with one of the any-of scopes and all
+// of the all-of scopes.
+func openapi_security(m MethodSpec) []map[string][]string {
+	alternatives := [][]string{}
+	for _, scope := range m.AnyScopes() {
+		alternatives = append(alternatives, append([]string{scope}, m.AllOfScopes...))
+	}
+	if len(alternatives) == 0 {
+		alternatives = append(alternatives, m.AllOfScopes)
+	}
+	security := []map[string][]string{}
+	for _, scheme := range []string{OpenApiBearerAuth, OpenApiQueryParamAuth} {
+		for _, scopes := range alternatives {
+			security = append(security, map[string][]string{scheme: scopes})
+		}
+	}
+	return security
+}
+
+func (this *schema_builder) content(types []string, schema *OpenApiSchema) map[string]OpenApiMediaType {
+	content := make(map[string]OpenApiMediaType)
+	for _, ct := range types {
+		content[ct] = OpenApiMediaType{Schema: schema}
+	}
+	return content
+}
+
+// Calls the factory to get a sample object.  The factories are normally called with the
+// http request so we guard against ones that actually dereference it.
+func (this *schema_builder) object_schema(factory ObjectFactory) (schema *OpenApiSchema) {
+	defer func() {
+		if r := recover(); r != nil {
+			schema = nil
+		}
+	}()
+	obj := factory(nil)
+	if obj == nil {
+		return nil
+	}
+	return this.schema_for(reflect.TypeOf(obj))
+}
+
+func (this *schema_builder) default_value_schema(v QueryDefault) *OpenApiSchema {
+	if v == nil {
+		return &OpenApiSchema{Type: "string"}
+	}
+	schema := this.schema_for(reflect.TypeOf(v))
+	copy := *schema
+	copy.Default = v
+	return &copy
+}
+
+func (this *schema_builder) schema_for(t reflect.Type) *OpenApiSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == time_type:
+		return &OpenApiSchema{Type: "string", Format: "date-time"}
+	case t == bytes_type:
+		return &OpenApiSchema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenApiSchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenApiSchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenApiSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenApiSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenApiSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &OpenApiSchema{Type: "array", Items: this.schema_for(t.Elem())}
+	case reflect.Map:
+		return &OpenApiSchema{Type: "object", AdditionalProperties: this.schema_for(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return this.struct_schema(t)
+		}
+		name := this.component_name(t)
+		if _, has := this.schemas[name]; !has {
+			// Register first so that recursive types terminate.
+			this.schemas[name] = &OpenApiSchema{}
+			*this.schemas[name] = *this.struct_schema(t)
+		}
+		return &OpenApiSchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &OpenApiSchema{}
+	}
+}
+
+func (this *schema_builder) component_name(t reflect.Type) string {
+	name := t.Name()
+	if seen, has := this.types[name]; has && seen != t {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+	}
+	this.types[name] = t
+	return name
+}
+
+func (this *schema_builder) struct_schema(t reflect.Type) *OpenApiSchema {
+	schema := &OpenApiSchema{
+		Type:       "object",
+		Properties: make(map[string]*OpenApiSchema),
+	}
+	this.add_fields(schema, t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (this *schema_builder) add_fields(schema *OpenApiSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || strings.HasPrefix(field.Name, "XXX_") {
+			continue // unexported or protobuf internals
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				this.add_fields(schema, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = this.schema_for(field.Type)
+		if pb := field.Tag.Get("protobuf"); strings.Contains(pb, ",req,") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func sorted_keys(m UrlQueries) []string {
+	keys := []string{}
+	for k, _ := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unique_strings(list []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Convenience for serving the document.  The YAML form is returned if requested via
+// the format query parameter or the Accept header.
+func (this *OpenApiDocument) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	var buff []byte
+	var err error
+	contentType := "application/json"
+	if req.URL.Query().Get("format") == "yaml" || strings.Contains(req.Header.Get("Accept"), "yaml") {
+		contentType = "application/yaml"
+		buff, err = this.ToYAML()
+	} else {
+		buff, err = this.ToJSON(true)
+	}
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	resp.Header().Set("Content-Type", contentType)
+	resp.Write(buff)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/tally"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type campaign struct {
+	Id      string    `json:"id"`
+	Title   string    `json:"title,omitempty"`
+	Created int64     `json:"created"`
+	Tags    []string  `json:"tags"`
+	Parent  *campaign `json:"parent,omitempty"`
+	Secret  string    `json:"-"`
+	hidden  string
+}
+
+const (
+	ListCampaigns ServiceMethod = iota
+	GetCampaign
+	PostEvent
+)
+
+var methods = ServiceMethods{
+	ListCampaigns: MethodSpec{
+		Doc:        "Lists campaigns",
+		UrlRoute:   "/api/v1/campaigns",
+		HttpMethod: GET,
+		UrlQueries: UrlQueries{"limit": 10, "active": true},
+		ResponseBody: func(req *http.Request) interface{} {
+			return []campaign{}
+		},
+	},
+	GetCampaign: MethodSpec{
+		Doc:         "Gets a campaign",
+		UrlRoute:    "/api/v1/campaign/{id:[0-9]+}",
+		HttpMethods: []HttpMethod{GET, HEAD},
+		AuthScope:   "campaigns",
+		ResponseBody: func(req *http.Request) interface{} {
+			return &campaign{}
+		},
+	},
+	PostEvent: MethodSpec{
+		Doc:          "Posts an event",
+		UrlRoute:     "/api/v1/event",
+		HttpMethod:   POST,
+		ContentTypes: []string{"application/json", "application/protobuf"},
+		AuthScope:    "events",
+		RequestBody: func(req *http.Request) interface{} {
+			return &tally.Event{}
+		},
+	},
+}
+
+func TestOpenApiPathsAndParameters(t *testing.T) {
+	doc := methods.OpenApi(OpenApiInfo{Title: "test", Version: "1.0"})
+
+	assert.Equal(t, OpenApiVersion, doc.OpenApi)
+	assert.Equal(t, 3, len(doc.Paths))
+
+	list := doc.Paths["/api/v1/campaigns"]["get"]
+	assert.NotEqual(t, (*OpenApiOperation)(nil), list)
+	assert.Equal(t, "get_api_v1_campaigns", list.OperationId)
+	assert.Equal(t, 2, len(list.Parameters))
+	assert.Equal(t, "active", list.Parameters[0].Name)
+	assert.Equal(t, "boolean", list.Parameters[0].Schema.Type)
+	assert.Equal(t, "limit", list.Parameters[1].Name)
+	assert.Equal(t, "integer", list.Parameters[1].Schema.Type)
+	assert.Equal(t, 10, list.Parameters[1].Schema.Default)
+	assert.Equal(t, 0, len(list.Security))
+
+	get := doc.Paths["/api/v1/campaign/{id}"]
+	assert.Equal(t, 2, len(get))
+	assert.Equal(t, "path", get["get"].Parameters[0].In)
+	assert.Equal(t, "^[0-9]+$", get["head"].Parameters[0].Schema.Pattern)
+	assert.Equal(t, []string{"campaigns"}, get["get"].Security[0][OpenApiBearerAuth])
+	assert.NotEqual(t, (*OpenApiResponse)(nil), get["get"].Responses["401"])
+}
+
+func TestOpenApiSchemas(t *testing.T) {
+	doc := methods.OpenApi(OpenApiInfo{Title: "test", Version: "1.0"})
+
+	c := doc.Components.Schemas["campaign"]
+	assert.NotEqual(t, (*OpenApiSchema)(nil), c)
+	assert.Equal(t, 5, len(c.Properties))
+	assert.Equal(t, "#/components/schemas/campaign", c.Properties["parent"].Ref)
+	assert.Equal(t, "array", c.Properties["tags"].Type)
+
+	list := doc.Paths["/api/v1/campaigns"]["get"].Responses["200"]
+	assert.Equal(t, "array", list.Content["application/json"].Schema.Type)
+
+	// Protobuf messages
+	post := doc.Paths["/api/v1/event"]["post"]
+	assert.Equal(t, 2, len(post.RequestBody.Content))
+	assert.Equal(t, "#/components/schemas/Event", post.RequestBody.Content["application/protobuf"].Schema.Ref)
+
+	event := doc.Components.Schemas["Event"]
+	assert.Equal(t, "number", event.Properties["timestamp"].Type)
+	assert.Equal(t, "#/components/schemas/Location", event.Properties["location"].Ref)
+	_, has := event.Properties["XXX_unrecognized"]
+	assert.Equal(t, false, has)
+	assert.NotEqual(t, 0, len(event.Required))
+
+	assert.Equal(t, 2, len(doc.Components.SecuritySchemes))
+	assert.Equal(t, "bearer", doc.Components.SecuritySchemes[OpenApiBearerAuth].Scheme)
+}
+
+func TestOpenApiYAML(t *testing.T) {
+	doc := methods.OpenApi(OpenApiInfo{Title: "test", Version: "1.0"})
+	buff, err := doc.ToYAML()
+	assert.Equal(t, nil, err)
+
+	text := string(buff)
+	t.Log(text)
+	assert.Equal(t, true, strings.HasPrefix(text, "openapi: 3.1.0\ninfo:\n  title: test\n  version: \"1.0\"\npaths:\n"))
+	assert.Equal(t, true, strings.Contains(text, "\n  /api/v1/campaign/{id}:\n"))
+	assert.Equal(t, true, strings.Contains(text, "\n      - name: id\n        in: path\n"))
+
+	decoded := OpenApiDocument{}
+	assert.Equal(t, nil, yaml.Unmarshal(buff, &decoded))
+	assert.Equal(t, "1.0", decoded.Info.Version)
+	assert.Equal(t, len(doc.Paths), len(decoded.Paths))
+}
+
+func TestOpenApiSecurity(t *testing.T) {
+	m := MethodSpec{
+		AuthScope:   "shorty:campaigns:read",
+		AnyOfScopes: []string{"admin"},
+		AllOfScopes: []string{"shorty:links:read"},
+	}
+	assert.Equal(t, true, m.RequiresAuth())
+	security := openapi_security(m)
+	assert.Equal(t, 4, len(security))
+	assert.Equal(t, []string{"shorty:campaigns:read", "shorty:links:read"}, security[0][OpenApiBearerAuth])
+	assert.Equal(t, []string{"admin", "shorty:links:read"}, security[1][OpenApiBearerAuth])
+	assert.Equal(t, []string{"admin", "shorty:links:read"}, security[3][OpenApiQueryParamAuth])
+
+	security = openapi_security(MethodSpec{AllOfScopes: []string{"a", "b"}})
+	assert.Equal(t, 2, len(security))
+	assert.Equal(t, []string{"a", "b"}, security[0][OpenApiBearerAuth])
+	assert.Equal(t, false, MethodSpec{}.RequiresAuth())
+}
@@ -0,0 +1,349 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraints on the url queries, form params, http headers and route variables of a
+// method, keyed by the same names used in the method spec.  The rules use the same syntax
+// as the `validate` struct tag used on the fields of request bodies:
+//
+//	required         value must be present / non-zero
+//	min=N, max=N     numeric bounds
+//	len=N            exact length of strings, slices and maps
+//	minlen=N         minimum length
+//	maxlen=N         maximum length
+//	enum=a|b|c       value must be one of the listed values
+//	regex=EXPR       strings must match; must be the last rule since EXPR may contain commas
+//
+// Nested structs, pointers to structs and slices of structs are validated recursively.
+type Constraints map[string]string
+
+const (
+	ValidateTag = "validate"
+)
+
+var (
+	ErrInvalidInput = errors.New("error-invalid-input")
+	ErrBadRule      = errors.New("error-bad-validation-rule")
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (this *ValidationError) Error() string {
+	messages := []string{}
+	for _, f := range this.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (this *ValidationError) Add(errors ...FieldError) {
+	this.Fields = append(this.Fields, errors...)
+}
+
+// Adds the field errors if the error is a validation error.  Any other error is returned.
+func (this *ValidationError) Collect(err error) error {
+	if verr, ok := err.(*ValidationError); ok {
+		this.Add(verr.Fields...)
+		return nil
+	}
+	return err
+}
+
+// Returns nil if there are no field errors so it can be returned directly as an error.
+func (this *ValidationError) OrNil() error {
+	if this == nil || len(this.Fields) == 0 {
+		return nil
+	}
+	return this
+}
+
+type rule struct {
+	name string
+	arg  string
+	num  float64
+	re   *regexp.Regexp
+}
+
+func parse_rules(spec string) ([]rule, error) {
+	rules := []rule{}
+	for len(spec) > 0 {
+		token := spec
+		if strings.HasPrefix(spec, "regex=") {
+			spec = ""
+		} else if i := strings.Index(spec, ","); i >= 0 {
+			token, spec = spec[:i], spec[i+1:]
+		} else {
+			spec = ""
+		}
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		r := rule{name: token}
+		if i := strings.Index(token, "="); i >= 0 {
+			r.name, r.arg = token[:i], token[i+1:]
+		}
+		switch r.name {
+		case "required":
+		case "min", "max", "len", "minlen", "maxlen":
+			n, err := strconv.ParseFloat(r.arg, 64)
+			if err != nil {
+				return nil, ErrBadRule
+			}
+			r.num = n
+		case "enum":
+			if r.arg == "" {
+				return nil, ErrBadRule
+			}
+		case "regex":
+			re, err := regexp.Compile(r.arg)
+			if err != nil {
+				return nil, ErrBadRule
+			}
+			r.re = re
+		default:
+			return nil, ErrBadRule
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Checks the rules are well formed.  This is meant to be called when binding the method.
+func (this Constraints) Check() error {
+	for k, spec := range this {
+		if _, err := parse_rules(spec); err != nil {
+			return errors.New(fmt.Sprintf("%s: %s", k, spec))
+		}
+	}
+	return nil
+}
+
+// Checks the `validate` tags of the object of the factory are well formed, in the nested
+// structs as well.  This is meant to be called when binding the method; the factory is
+// called without a request, as for the OpenAPI document.
+func CheckValidateTags(factory ObjectFactory) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = nil
+		}
+	}()
+	obj := factory(nil)
+	if obj == nil {
+		return nil
+	}
+	return check_tags("", reflect.TypeOf(obj), map[reflect.Type]bool{})
+}
+
+func check_tags(prefix string, t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == time_type || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := prefix + field.Name
+		if spec := field.Tag.Get(ValidateTag); spec != "" {
+			if _, err := parse_rules(spec); err != nil {
+				return errors.New(fmt.Sprintf("%s: %s", name, spec))
+			}
+		}
+		if err := check_tags(name+".", field.Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validates the value of the named input.  Present is false if the input is missing
+// from the request, in which case only the required rule applies.
+func (this Constraints) Validate(field string, present bool, value interface{}) []FieldError {
+	spec, has := this[field]
+	if !has {
+		return nil
+	}
+	if !present {
+		return check_rules(field, spec, false, reflect.Value{})
+	}
+	return check_rules(field, spec, true, reflect.ValueOf(value))
+}
+
+// Validates the fields of the struct according to their `validate` tags.
+func ValidateStruct(v interface{}) []FieldError {
+	if v == nil {
+		return nil
+	}
+	return validate_value("", reflect.ValueOf(v))
+}
+
+func validate_value(prefix string, v reflect.Value) []FieldError {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	result := []FieldError{}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == time_type {
+			return nil
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				name = field.Name
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			fv := v.Field(i)
+			if spec := field.Tag.Get(ValidateTag); spec != "" {
+				result = append(result, check_rules(name, spec, !is_zero(fv), fv)...)
+			}
+			result = append(result, validate_value(name, fv)...)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			result = append(result, validate_value(fmt.Sprintf("%s[%d]", prefix, i), v.Index(i))...)
+		}
+	}
+	return result
+}
+
+// Present is used for the required rule.  The other rules are skipped if the value is
+// missing or a nil pointer; zero values of scalars are still checked.
+func check_rules(field, spec string, present bool, v reflect.Value) []FieldError {
+	rules, err := parse_rules(spec)
+	if err != nil {
+		return []FieldError{{Field: field, Rule: "rule", Message: err.Error()}}
+	}
+	result := []FieldError{}
+	fail := func(r rule, format string, args ...interface{}) {
+		result = append(result, FieldError{Field: field, Rule: r.name, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, r := range rules {
+		if r.name == "required" {
+			if !present {
+				fail(r, "is required")
+				return result
+			}
+		}
+	}
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return result
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return result
+	}
+	for _, r := range rules {
+		switch r.name {
+		case "min", "max":
+			n, ok := to_number(v)
+			if !ok {
+				fail(r, "is not a number")
+			} else if r.name == "min" && n < r.num {
+				fail(r, "must be at least %v", r.arg)
+			} else if r.name == "max" && n > r.num {
+				fail(r, "must be at most %v", r.arg)
+			}
+		case "len", "minlen", "maxlen":
+			l, ok := to_length(v)
+			switch {
+			case !ok:
+				fail(r, "has no length")
+			case r.name == "len" && float64(l) != r.num:
+				fail(r, "must have length %v", r.arg)
+			case r.name == "minlen" && float64(l) < r.num:
+				fail(r, "must have length at least %v", r.arg)
+			case r.name == "maxlen" && float64(l) > r.num:
+				fail(r, "must have length at most %v", r.arg)
+			}
+		case "enum":
+			s := fmt.Sprintf("%v", v.Interface())
+			found := false
+			for _, option := range strings.Split(r.arg, "|") {
+				if s == option {
+					found = true
+					break
+				}
+			}
+			if !found {
+				fail(r, "must be one of %s", strings.Replace(r.arg, "|", ", ", -1))
+			}
+		case "regex":
+			if v.Kind() != reflect.String {
+				fail(r, "is not a string")
+			} else if !r.re.MatchString(v.String()) {
+				fail(r, "must match %s", r.arg)
+			}
+		}
+	}
+	return result
+}
+
+func is_zero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	default:
+		return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+	}
+}
+
+func to_number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		// The route variables, headers and queries without a typed default
+		n, err := strconv.ParseFloat(v.String(), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func to_length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return len([]rune(v.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"testing"
+)
+
+type address struct {
+	Zip string `json:"zip" validate:"required,regex=^[0-9]{5}$"`
+}
+
+type signup struct {
+	Email     string    `json:"email" validate:"required,maxlen=64,regex=^[^@]+@[^@]+$"`
+	Age       int       `json:"age" validate:"min=13,max=120"`
+	Plan      string    `json:"plan" validate:"enum=free|pro"`
+	Nickname  *string   `json:"nickname,omitempty" validate:"minlen=2"`
+	Address   *address  `json:"address"`
+	Addresses []address `json:"addresses" validate:"maxlen=2"`
+}
+
+func fields(errors []FieldError) map[string]string {
+	m := map[string]string{}
+	for _, e := range errors {
+		m[e.Field] = e.Rule
+	}
+	return m
+}
+
+func TestValidateStruct(t *testing.T) {
+	ok := &signup{Email: "a@b.com", Age: 20, Plan: "pro", Address: &address{Zip: "94110"}}
+	assert.Equal(t, 0, len(ValidateStruct(ok)))
+
+	short := "x"
+	bad := &signup{
+		Age:       5,
+		Plan:      "gold",
+		Nickname:  &short,
+		Address:   &address{Zip: "abc"},
+		Addresses: []address{{Zip: "94110"}, {}, {Zip: "1"}},
+	}
+	errors := fields(ValidateStruct(bad))
+	t.Log(errors)
+	assert.Equal(t, "required", errors["email"])
+	assert.Equal(t, "min", errors["age"])
+	assert.Equal(t, "enum", errors["plan"])
+	assert.Equal(t, "minlen", errors["nickname"])
+	assert.Equal(t, "regex", errors["address.zip"])
+	assert.Equal(t, "maxlen", errors["addresses"])
+	assert.Equal(t, "required", errors["addresses[1].zip"])
+	assert.Equal(t, "regex", errors["addresses[2].zip"])
+	assert.Equal(t, 8, len(errors))
+}
+
+func TestConstraints(t *testing.T) {
+	c := Constraints{
+		"limit": "min=1,max=100",
+		"sort":  "required,enum=asc|desc",
+		"q":     "regex=^[a-z,]+$",
+	}
+	assert.Equal(t, nil, c.Check())
+	assert.NotEqual(t, nil, Constraints{"x": "between=1"}.Check())
+	assert.NotEqual(t, nil, Constraints{"x": "regex=("}.Check())
+
+	assert.Equal(t, 0, len(c.Validate("limit", true, 10)))
+	assert.Equal(t, "max", c.Validate("limit", true, 1000)[0].Rule)
+	assert.Equal(t, 0, len(c.Validate("limit", false, 0)))
+	assert.Equal(t, "required", c.Validate("sort", false, "")[0].Rule)
+	assert.Equal(t, 0, len(c.Validate("q", true, "a,b")))
+	assert.Equal(t, 1, len(c.Validate("q", true, "A")))
+	assert.Equal(t, 0, len(c.Validate("other", true, "A")))
+
+	// The route variables, headers and queries without a typed default are strings
+	assert.Equal(t, 0, len(c.Validate("limit", true, "5")))
+	assert.Equal(t, "max", c.Validate("limit", true, "500")[0].Rule)
+	assert.Equal(t, "is not a number", c.Validate("limit", true, "five")[0].Message)
+}
+
+type bad_tag struct {
+	Address *address `json:"address"`
+	Items   []struct {
+		Count int `validate:"min=one"`
+	}
+}
+
+func TestCheckValidateTags(t *testing.T) {
+	assert.Equal(t, nil, CheckValidateTags(func(*http.Request) interface{} { return &signup{} }))
+	err := CheckValidateTags(func(*http.Request) interface{} { return &bad_tag{} })
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "Items.Count: min=one", err.Error())
+}
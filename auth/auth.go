@@ -5,7 +5,6 @@ import (
 	"flag"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
-	"io/ioutil"
 	"net/http"
 	"time"
 )
@@ -31,15 +30,48 @@ type CheckScope func(string, []string) bool
 type SignKey func() []byte
 type VerifyKey func() []byte
 
-// Sign keys and verifcation keys are both function of the input which is the http request.
+// The settings of the auth service.  The zero values turn the optional features off.
 type Settings struct {
-	TTLHours                 time.Duration
-	IsAuthOn                 IsAuthOn
-	CheckScope               CheckScope
+	TTLHours        time.Duration
+	RefreshTTLHours time.Duration
+	// Lets the tokens be revoked and refreshed, see refresh.go.
+	RevocationStore RevocationStore
+	IsAuthOn        IsAuthOn
+	// Checks the scopes when there is no policy.
+	CheckScope CheckScope
+	// Checks the scopes, see scopes.go.
+	Policy *Policy
+	// HS256 by default, see signing.go for the keys.
+	SigningMethod string
+	// Signs the tokens with its active key and verifies them by their kid instead of the
+	// keys of the http requests; the tokens without a kid are still verified with the
+	// verify key if any.
+	KeySet  *KeySet
+	Keyring *Keyring
+	// The first source that has a token is used, by default the Authorization header and
+	// then the access_token query parameter.
+	TokenSources []TokenSource
+	// Carried by the new tokens, and then required of the tokens, or one of the accepted issuers.
+	Issuer          string
+	AcceptedIssuers []string
+	// Set on the new tokens, and then required of the tokens, see claims.go.
+	Audience string
+	// Requires an audience even without one in the settings, e.g. from the service id of the
+	// endpoints.
+	RequireAudience bool
+	// The leeway of the registered claims, see claims.go.
+	ClockSkew time.Duration
+	// The tokens are issued for the logins of these accounts, see login.go.
+	Credentials   CredentialStore
+	Passwords     *Passwords
+	LoginThrottle *LoginThrottle
+	// The keys are both function of the http request.
 	SignKeyFromHttpRequest   func(*http.Request) []byte
 	VerifyKeyFromHttpRequest func(*http.Request) []byte
-	ErrorRenderer            func(http.ResponseWriter, *http.Request, string, int) error
-	AuthIntercept            func(bool, Context) (bool, Context)
+	// Given the error code and the http status.  The errors are rendered as json by default,
+	// and rest.ErrorRenderer renders them in the negotiated content type.
+	ErrorRenderer func(http.ResponseWriter, *http.Request, string, int) error
+	AuthIntercept func(bool, Context) (bool, Context)
 }
 
 type HttpHandler func(auth Context, resp http.ResponseWriter, req *http.Request)
@@ -56,6 +88,7 @@ type Service interface {
 
 type serviceImpl struct {
 	settings   Settings
+	method     jwt.SigningMethod
 	GetTime    func() time.Time
 	IsAuthOn   IsAuthOn
 	CheckScope CheckScope
@@ -65,17 +98,18 @@ type Token struct {
 	token *jwt.Token
 }
 
-func ReadPublicKey(filename string) (key []byte, err error) {
-	// TODO -- this really isn't doing anything like parsing
-	// a proper file format like X.509 or anything.
-
-	key, err = ioutil.ReadFile(filename)
-	return
-}
-
+// Panics if the signing method of the settings is unknown.
 func Init(settings Settings) *serviceImpl {
+	method, err := signing_method(settings.SigningMethod)
+	if err != nil {
+		panic(fmt.Errorf("%s: %s", err, settings.SigningMethod))
+	}
+	if settings.TokenSources == nil {
+		settings.TokenSources = DefaultTokenSources
+	}
 	return &serviceImpl{
 		settings:   settings,
+		method:     method,
 		GetTime:    func() time.Time { return time.Now() },
 		IsAuthOn:   settings.IsAuthOn,
 		CheckScope: settings.CheckScope,
@@ -83,7 +117,15 @@ func Init(settings Settings) *serviceImpl {
 }
 
 func (this *serviceImpl) NewToken() (token *Token) {
-	token = &Token{token: jwt.New(jwt.GetSigningMethod("HS256"))}
+	token = &Token{token: jwt.New(this.method)}
+	token.Add(JtiClaim, new_token_id())
+	token.Add("iat", time.Now().Unix())
+	if this.settings.Issuer != "" {
+		token.Add("iss", this.settings.Issuer)
+	}
+	if this.settings.Audience != "" {
+		token.SetAudience(this.settings.Audience)
+	}
 	token.SetExpiration(time.Hour * this.settings.TTLHours)
 	return token
 }
@@ -98,7 +140,11 @@ func (this *serviceImpl) SignedString(token *Token, f SignKey) (tokenString stri
 	if f == nil {
 		return "", ErrNoSignKey
 	}
-	tokenString, err = token.token.SignedString(f())
+	key, err := signing_key(token.token.Method.Alg(), f())
+	if err != nil {
+		return "", err
+	}
+	tokenString, err = token.token.SignedString(key)
 	return
 }
 
@@ -106,15 +152,11 @@ func (this *serviceImpl) check_token(t *jwt.Token) (*Token, error) {
 	if t == nil || !t.Valid {
 		return nil, InvalidToken
 	}
-	// Check expiration if there is one
-	if expClaim, has := t.Claims["exp"]; has {
-		exp, ok := expClaim.(float64)
-		if !ok {
-			return nil, InvalidToken
-		}
-		if this.GetTime().After(time.Unix(int64(exp), 0)) {
-			return nil, ExpiredToken
-		}
+	if err := this.check_times(t.Claims); err != nil {
+		return nil, err
+	}
+	if err := this.check_issuer(t.Claims); err != nil {
+		return nil, err
 	}
 	return &Token{token: t}, nil
 }
@@ -123,15 +165,31 @@ func (this *serviceImpl) Parse(tokenString string, f VerifyKey) (token *Token, e
 	if f == nil {
 		return nil, ErrNoVerifyKey
 	}
-	t, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		return f(), nil
-	})
-	if err != nil {
+	return this.parse(tokenString, this.keyfunc(f))
+}
+
+func (this *serviceImpl) parse(tokenString string, keyfunc jwt.Keyfunc) (*Token, error) {
+	t, err := jwt.Parse(tokenString, keyfunc)
+	switch {
+	case err != nil && t != nil && time_errors_only(err):
+		// Checked with the leeway by check_token
+		t.Valid = true
+	case err != nil:
 		return nil, err
 	}
 	return this.check_token(t)
 }
 
+func (this *serviceImpl) keyfunc(f VerifyKey) jwt.Keyfunc {
+	return func(t *jwt.Token) (interface{}, error) {
+		// Only the configured method, e.g. so that a public key is never used as an HMAC secret
+		if t.Method.Alg() != this.method.Alg() {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		return verification_key(t.Method.Alg(), f())
+	}
+}
+
 func (this *Token) Add(key string, value interface{}) *Token {
 	this.token.Claims[key] = value
 	return this
@@ -4,13 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/api"
+	omni_http "github.com/qorio/omni/http"
+	"github.com/qorio/omni/logging"
 	"net/http"
 	"strings"
 )
 
 var (
-	ErrNoAuthToken = errors.New("no-auth-token")
+	ErrNoAuthToken   = errors.New("no-auth-token")
+	ErrBadAuthHeader = errors.New("bad-authorization-header")
+
+	logger = logging.For("auth")
 )
 
 type Context interface {
@@ -24,66 +29,178 @@ type context struct {
 	token *Token
 }
 
+// The context has no token when auth is off.
 func (this *context) HasKey(key string) bool {
-	return this.token.HasKey(key)
+	return this.token != nil && this.token.HasKey(key)
 }
 
 func (this *context) GetString(key string) string {
+	if this.token == nil {
+		return ""
+	}
 	return this.token.GetString(key)
 }
 
 func (this *context) GetStringForService(service, key string) string {
-	return this.token.GetString(fmt.Sprintf("%s/%s", service, key))
+	return this.GetString(fmt.Sprintf("%s/%s", service, key))
 }
 
 func (this *context) Get(key string) interface{} {
+	if this.token == nil {
+		return nil
+	}
 	return this.token.Get(key)
 }
 
-func (service *serviceImpl) get_token_from_header(req *http.Request) (*Token, error) {
-	if service.settings.VerifyKeyFromHttpRequest == nil {
-		return nil, ErrNoVerifyKey
+// Returns the token string of the request, or ErrNoAuthToken if the source has none.
+type TokenSource func(*http.Request) (string, error)
+
+var DefaultTokenSources = []TokenSource{AuthorizationHeader(), QueryParam("access_token")}
+
+// The token of the Authorization header, in the form "<scheme> <token>" where the scheme
+// is Bearer, Token or OAuth.
+func AuthorizationHeader() TokenSource {
+	return func(req *http.Request) (string, error) {
+		header := strings.TrimSpace(req.Header.Get("Authorization"))
+		if header == "" {
+			return "", ErrNoAuthToken
+		}
+		parts := strings.SplitN(header, " ", 2)
+		if len(parts) != 2 {
+			return "", ErrBadAuthHeader
+		}
+		switch strings.ToLower(parts[0]) {
+		case "bearer", "token", "oauth":
+			return strings.TrimSpace(parts[1]), nil
+		}
+		return "", ErrBadAuthHeader
+	}
+}
+
+// The token in the value of the header.
+func Header(name string) TokenSource {
+	return func(req *http.Request) (string, error) {
+		if v := strings.TrimSpace(req.Header.Get(name)); v != "" {
+			return v, nil
+		}
+		return "", ErrNoAuthToken
 	}
-	// Format:  Authorization: Bearer|token|Oauth + ' ' + <token>
-	header := req.Header.Get("Authorization")
-	if header == "" {
-		return nil, ErrNoAuthToken
+}
+
+// The token in the query parameter of the url.
+func QueryParam(name string) TokenSource {
+	return func(req *http.Request) (string, error) {
+		if v := req.URL.Query().Get(name); v != "" {
+			return v, nil
+		}
+		return "", ErrNoAuthToken
+	}
+}
+
+// The token in the plain cookie.
+func Cookie(name string) TokenSource {
+	return func(req *http.Request) (string, error) {
+		if cookie, err := req.Cookie(name); err == nil && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+		return "", ErrNoAuthToken
+	}
+}
+
+// The token in the cookie encrypted by the secure cookie, as set with SetCookie.
+func SecureCookie(secureCookie *omni_http.SecureCookie, name string) TokenSource {
+	return func(req *http.Request) (string, error) {
+		var v string
+		if err := secureCookie.ReadCookie(req, name, &v, true); err != nil {
+			return "", err
+		}
+		if v == "" {
+			return "", ErrNoAuthToken
+		}
+		return v, nil
 	}
-	tokenString := strings.Trim(strings.SplitAfterN(header, " ", 2)[1], " ")
-	return service.Parse(tokenString, func() []byte {
-		return service.settings.VerifyKeyFromHttpRequest(req)
-	})
 }
 
-// This is the preferred implemented using a method provided in the jwt library directly.
-func (this *serviceImpl) get_token_from_header_query_param(req *http.Request) (*Token, error) {
-	if this.settings.VerifyKeyFromHttpRequest == nil {
+// The token of the first token source that has one.
+func (this *serviceImpl) get_token_from_request(req *http.Request) (*Token, error) {
+	if this.settings.VerifyKeyFromHttpRequest == nil && this.settings.KeySet == nil {
 		return nil, ErrNoVerifyKey
 	}
-	t, err := jwt.ParseFromRequest(req, func(*jwt.Token) (interface{}, error) {
-		return this.settings.VerifyKeyFromHttpRequest(req), nil
-	})
-	if err != nil {
-		return nil, err
+	for _, source := range this.settings.TokenSources {
+		tokenString, err := source(req)
+		if err == ErrNoAuthToken {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		token, err := this.ParseForHttpRequest(tokenString, req)
+		if err != nil {
+			return nil, err
+		}
+		if token.IsRefreshToken() {
+			return nil, ErrRefreshTokenAsToken
+		}
+		if !token.IsAccessToken() {
+			return nil, ErrNotAccessToken
+		}
+		if err := this.check_revoked(token); err != nil {
+			return nil, err
+		}
+		return token, nil
 	}
-	return this.check_token(t)
+	return nil, ErrNoAuthToken
 }
 
 func (this *serviceImpl) ParseForHttpRequest(tokenString string, req *http.Request) (token *Token, err error) {
-	if this.settings.SignKeyFromHttpRequest == nil {
+	var legacy jwt.Keyfunc
+	if this.settings.VerifyKeyFromHttpRequest != nil {
+		legacy = this.keyfunc(func() []byte { return this.settings.VerifyKeyFromHttpRequest(req) })
+	}
+	keys := this.settings.KeySet
+	switch {
+	case keys == nil && legacy == nil:
 		return nil, ErrNoVerifyKey
+	case keys == nil:
+		return this.parse(tokenString, legacy)
 	}
-	return this.Parse(tokenString, func() []byte { return this.settings.VerifyKeyFromHttpRequest(req) })
+	return this.parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, has := t.Header["kid"]; !has && legacy != nil {
+			// Signed before the key set
+			return legacy(t)
+		}
+		return keys.keyfunc(t)
+	})
 }
 
 func (this *serviceImpl) SignedStringForHttpRequest(token *Token, req *http.Request) (tokenString string, err error) {
+	if this.settings.KeySet != nil {
+		return this.settings.KeySet.sign(token)
+	}
 	if this.settings.SignKeyFromHttpRequest == nil {
 		return "", ErrNoSignKey
 	}
 	return this.SignedString(token, func() []byte { return this.settings.SignKeyFromHttpRequest(req) })
 }
 
+// Requires the audience of the settings, if any.
 func (service *serviceImpl) RequiresAuth(scope string, get_scopes GetScopesFromToken, handler HttpHandler) func(http.ResponseWriter, *http.Request) {
+	return service.RequiresAuthForAudience(service.settings.Audience, scope, get_scopes, handler)
+}
+
+// Requires the tokens to be meant for the audience as well; the audience of the settings
+// is the default.
+func (service *serviceImpl) RequiresAuthForAudience(audience, scope string, get_scopes GetScopesFromToken, handler HttpHandler) func(http.ResponseWriter, *http.Request) {
+	return service.RequiresScopes(audience, Requirement{AnyOf: []string{scope}}, get_scopes, handler)
+}
+
+// Requires the tokens to be meant for the audience, and to meet the requirement on the scopes,
+// with the policy of the settings if any.
+func (service *serviceImpl) RequiresScopes(audience string, requirement Requirement, get_scopes GetScopesFromToken, handler HttpHandler) func(http.ResponseWriter, *http.Request) {
+	if audience == "" {
+		audience = service.settings.Audience
+	}
+	debug := service.settings.Policy != nil && service.settings.Policy.Debug
 	return func(resp http.ResponseWriter, req *http.Request) {
 		info := context{}
 		checkAuth := true
@@ -95,31 +212,31 @@ func (service *serviceImpl) RequiresAuth(scope string, get_scopes GetScopesFromT
 
 		authed := false
 		if checkAuth {
-			token, err := service.get_token_from_header_query_param(req)
+			token, err := service.get_token_from_request(req)
+			if err == nil {
+				err = service.check_audience(token, audience)
+			}
 			if err != nil {
-				glog.Warningln("auth-error", err)
-				renderError(resp, req, err.Error(), http.StatusUnauthorized)
+				logger.ForRequest(req).Warning("auth-error", "error", err, "path", req.URL.Path)
+				service.render_error(resp, req, err.Error(), http.StatusUnauthorized)
 				return
 			}
 			info.token = token
 
-			// Check the scope
-			scopes := []string{}
+			// Check the scopes, and the roles of the same service
+			scopes, roles_service := []string{}, ""
 			if get_scopes != nil {
-				scopes = get_scopes(info.token)
+				scopes, roles_service = get_scopes(info.token), audience
 			} else {
 				scopes = strings.Split(info.token.GetString("@scopes"), ",")
 			}
 
-			if service.CheckScope != nil {
-				authed = service.CheckScope(scope, scopes)
-			} else {
-				for _, s := range scopes {
-					if s == scope {
-						authed = true
-						break
-					}
-				}
+			decision := service.decide(requirement, info.token, scopes, roles_service)
+			authed = decision.Allowed
+			if debug {
+				logger.ForRequest(req).Info("auth-decision", "requirement", requirement,
+					"decision", decision, "path", req.URL.Path)
+				resp.Header().Set(AuthDecisionHeader, decision.String())
 			}
 		} else {
 			authed = true
@@ -134,20 +251,16 @@ func (service *serviceImpl) RequiresAuth(scope string, get_scopes GetScopesFromT
 			handler(ctx, resp, req)
 			return
 		} else {
-			// error
-			if service.settings.ErrorRenderer != nil {
-				service.settings.ErrorRenderer(resp, req, "not-permitted", http.StatusUnauthorized)
-			} else {
-				renderError(resp, req, "not-permitted", http.StatusUnauthorized)
-			}
+			service.render_error(resp, req, "not-permitted", http.StatusUnauthorized)
 			return
 		}
 	}
 }
 
-// The default renderer
-func renderError(resp http.ResponseWriter, req *http.Request, message string, code int) (err error) {
-	resp.WriteHeader(code)
-	resp.Write([]byte(fmt.Sprintf("<html><body>Error: %s </body></html>", message)))
-	return
+// Renders with the error renderer of the settings, or as json by default.
+func (service *serviceImpl) render_error(resp http.ResponseWriter, req *http.Request, code string, status int) error {
+	if service.settings.ErrorRenderer != nil {
+		return service.settings.ErrorRenderer(resp, req, code, status)
+	}
+	return api.RenderJSONError(resp, req, code, status)
 }
@@ -1,14 +1,28 @@
 package auth
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"flag"
+	"github.com/bmizerany/assert"
+	omni_http "github.com/qorio/omni/http"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
 	"time"
 )
 
 var (
-	authKeyFile = flag.String("auth_public_key_file", "", "Auth public key file")
+	authKeyFile = flag.String("auth_public_key_file", "../test/authKey.pub", "Auth public key file")
 )
 
 func TestNewToken(t *testing.T) {
@@ -95,3 +109,123 @@ func TestGetAppTokenAuthRsaKey(t *testing.T) {
 		t.Error("expecting", id, "but got", appKey)
 	}
 }
+
+func pem_keys(t *testing.T, private interface{}) (sign, verify []byte) {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	public := private.(interface{ Public() crypto.PublicKey }).Public()
+	if der, err = x509.MarshalPKIXPublicKey(public); err != nil {
+		t.Fatal(err)
+	}
+	verify = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	return
+}
+
+func TestAsymmetricSigningMethods(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for method, private := range map[string]interface{}{RS256: rsaKey, ES256: ecKey} {
+		sign, verify := pem_keys(t, private)
+		auth := Init(Settings{SigningMethod: method})
+
+		encoded, err := auth.SignedString(auth.NewToken().Add("appKey", "1234"), func() []byte { return sign })
+		assert.Equal(t, nil, err)
+
+		parsed, err := auth.Parse(encoded, func() []byte { return verify })
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "1234", parsed.GetString("appKey"))
+
+		// The public key cannot be used as an HMAC secret
+		hs := Init(Settings{})
+		forged, err := hs.SignedString(hs.NewToken().Add("appKey", "1234"), func() []byte { return verify })
+		assert.Equal(t, nil, err)
+		_, err = auth.Parse(forged, func() []byte { return verify })
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestReadPublicKeyFromCertificate(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	assert.Equal(t, nil, err)
+
+	f, err := ioutil.TempFile("", "cert")
+	assert.Equal(t, nil, err)
+	defer os.Remove(f.Name())
+	pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	f.Close()
+
+	public, err := ReadPublicKey(f.Name())
+	assert.Equal(t, nil, err)
+	parsed, err := ParsePublicKey(public)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, key.X, parsed.(*ecdsa.PublicKey).X)
+
+	_, err = ParsePublicKey([]byte("not a key"))
+	assert.Equal(t, ErrKeyNotPEM, err)
+	_, err = ParsePublicKey([]byte("ssh-rsa AAAAB3NzaC1yc2E="))
+	assert.Equal(t, ErrKeyNotPEM, err)
+}
+
+func TestReadPublicKeyFromSshRsa(t *testing.T) {
+	public, err := ReadPublicKey("../test/authKey.pub")
+	assert.Equal(t, nil, err)
+	parsed, err := ParsePublicKey(public)
+	assert.Equal(t, nil, err)
+
+	data, err := ReadPrivateKey("../test/authKey")
+	assert.Equal(t, nil, err)
+	private, err := ParsePrivateKey(data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, private.(*rsa.PrivateKey).PublicKey, *parsed.(*rsa.PublicKey))
+}
+
+func TestTokenSources(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	secureCookie, _ := omni_http.NewSecureCookie([]byte("hmac"), nil)
+	auth := Init(Settings{
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		TokenSources: []TokenSource{
+			Header("X-Token"),
+			SecureCookie(secureCookie, "token"),
+			AuthorizationHeader(),
+		},
+	})
+	sign := func(claim string) string {
+		s, _ := auth.SignedString(auth.NewToken().Add("source", claim), func() []byte { return []byte("test") })
+		return s
+	}
+
+	req, _ := http.NewRequest("GET", "/?access_token="+sign("query"), nil)
+	_, err := auth.get_token_from_request(req)
+	assert.Equal(t, ErrNoAuthToken, err)
+
+	req.Header.Set("Authorization", "Bearer "+sign("authorization"))
+	token, err := auth.get_token_from_request(req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "authorization", token.GetString("source"))
+
+	recorder := httptest.NewRecorder()
+	secureCookie.SetCookie(recorder, "token", sign("cookie"), true)
+	req.Header.Set("Cookie", recorder.Header().Get("Set-Cookie"))
+	token, err = auth.get_token_from_request(req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "cookie", token.GetString("source"))
+
+	req.Header.Set("X-Token", sign("header"))
+	token, err = auth.get_token_from_request(req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "header", token.GetString("source"))
+
+	// A header without a token is an error, not a panic
+	req, _ = http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer")
+	_, err = auth.get_token_from_request(req)
+	assert.Equal(t, ErrBadAuthHeader, err)
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"net/http"
+	"time"
+)
+
+// Validation of the registered claims: exp, nbf and iat with the clock skew leeway of the
+// settings, iss against the accepted issuers, and aud against the service of the endpoint.
+
+const (
+	SubjectClaim = "sub"
+)
+
+var (
+	ErrTokenNotYetValid    = errors.New("token-not-yet-valid")
+	ErrTokenIssuedInFuture = errors.New("token-issued-in-future")
+	ErrTokenBadTime        = errors.New("token-bad-time-claim")
+	ErrTokenBadIssuer      = errors.New("token-bad-issuer")
+	ErrTokenBadAudience    = errors.New("token-bad-audience")
+	ErrTokenNoAudience     = errors.New("token-no-audience")
+)
+
+// Implemented by the services checking that the tokens are meant for the service of the
+// endpoint, e.g. the service id of the rest methods.
+type AudienceService interface {
+	RequiresAuthForAudience(audience, scope string, get_scopes GetScopesFromToken, handler HttpHandler) func(http.ResponseWriter, *http.Request)
+}
+
+// Implemented by the services checking the requirements on the scopes, see scopes.go.
+type RequirementService interface {
+	RequiresScopes(audience string, requirement Requirement, get_scopes GetScopesFromToken, handler HttpHandler) func(http.ResponseWriter, *http.Request)
+}
+
+// The time claim as a time, false if there is none.
+func time_claim(claims map[string]interface{}, key string) (time.Time, bool, error) {
+	value, has := claims[key]
+	if !has {
+		return time.Time{}, false, nil
+	}
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true, nil
+	case int64:
+		return time.Unix(v, 0), true, nil
+	}
+	return time.Time{}, false, ErrTokenBadTime
+}
+
+// Clears the errors of the times checked by jwt-go, without leeway, since the times are
+// validated by check_times.  The other errors, e.g. of the signature, are kept.
+func time_errors_only(err error) bool {
+	v, ok := err.(*jwt.ValidationError)
+	return ok && v.Errors&^(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) == 0
+}
+
+func (this *serviceImpl) check_times(claims map[string]interface{}) error {
+	now := this.GetTime()
+	leeway := this.settings.ClockSkew
+	exp, has, err := time_claim(claims, "exp")
+	if err != nil {
+		return InvalidToken
+	}
+	if has && now.After(exp.Add(leeway)) {
+		return ExpiredToken
+	}
+	nbf, has, err := time_claim(claims, "nbf")
+	if err != nil {
+		return InvalidToken
+	}
+	if has && now.Add(leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+	iat, has, err := time_claim(claims, "iat")
+	if err != nil {
+		return InvalidToken
+	}
+	if has && now.Add(leeway).Before(iat) {
+		return ErrTokenIssuedInFuture
+	}
+	return nil
+}
+
+// The issuer must be one of the accepted issuers, by default the issuer of the settings.
+// Not checked if there are none.
+func (this *serviceImpl) check_issuer(claims map[string]interface{}) error {
+	issuers := this.settings.AcceptedIssuers
+	if len(issuers) == 0 && this.settings.Issuer != "" {
+		issuers = []string{this.settings.Issuer}
+	}
+	if len(issuers) == 0 {
+		return nil
+	}
+	iss, _ := claims["iss"].(string)
+	for _, accepted := range issuers {
+		if iss == accepted {
+			return nil
+		}
+	}
+	return ErrTokenBadIssuer
+}
+
+// The audience must include the audience if the token has one, or always if the audience
+// is required by the settings.
+func (this *serviceImpl) check_audience(token *Token, audience string) error {
+	if audience == "" {
+		return nil
+	}
+	audiences := token.Audience()
+	if len(audiences) == 0 {
+		// Tokens without audience are only accepted by the services without one, e.g.
+		// from the service id of their endpoints, until the audience is required
+		if this.settings.Audience != "" || this.settings.RequireAudience {
+			return ErrTokenNoAudience
+		}
+		return nil
+	}
+	for _, aud := range audiences {
+		if aud == audience {
+			return nil
+		}
+	}
+	return ErrTokenBadAudience
+}
+
+// Sets the aud claim, a string for a single audience.
+func (this *Token) SetAudience(audiences ...string) *Token {
+	switch len(audiences) {
+	case 0:
+		delete(this.token.Claims, "aud")
+	case 1:
+		this.token.Claims["aud"] = audiences[0]
+	default:
+		this.token.Claims["aud"] = audiences
+	}
+	return this
+}
+
+// The audiences of the aud claim, a string or a list of strings.
+func (this *Token) Audience() []string {
+	switch aud := this.Get("aud").(type) {
+	case string:
+		return []string{aud}
+	case []string:
+		return aud
+	case []interface{}:
+		audiences := []string{}
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+		return audiences
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeClaims(t *testing.T) {
+	key := func() []byte { return []byte("test") }
+	auth := Init(Settings{TTLHours: 1, ClockSkew: time.Minute})
+	now := time.Now()
+	auth.GetTime = func() time.Time { return now }
+
+	parse := func(claims map[string]interface{}) error {
+		token := auth.NewToken()
+		for k, v := range claims {
+			token.Add(k, v)
+		}
+		encoded, err := auth.SignedString(token, key)
+		assert.Equal(t, nil, err)
+		_, err = auth.Parse(encoded, key)
+		return err
+	}
+
+	assert.Equal(t, nil, parse(nil))
+	// Within the leeway
+	assert.Equal(t, nil, parse(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}))
+	assert.Equal(t, nil, parse(map[string]interface{}{"nbf": now.Add(30 * time.Second).Unix()}))
+	assert.Equal(t, nil, parse(map[string]interface{}{"iat": now.Add(30 * time.Second).Unix()}))
+
+	assert.Equal(t, ExpiredToken, parse(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()}))
+	assert.Equal(t, ErrTokenNotYetValid, parse(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()}))
+	assert.Equal(t, ErrTokenIssuedInFuture, parse(map[string]interface{}{"iat": now.Add(2 * time.Minute).Unix()}))
+	assert.Equal(t, InvalidToken, parse(map[string]interface{}{"iat": "yesterday"}))
+}
+
+func TestIssuer(t *testing.T) {
+	key := func() []byte { return []byte("test") }
+	issuer := Init(Settings{Issuer: "accounts"})
+	other := Init(Settings{Issuer: "other"})
+
+	token := issuer.NewToken()
+	assert.Equal(t, "accounts", token.GetString("iss"))
+	encoded, _ := issuer.SignedString(token, key)
+
+	_, err := issuer.Parse(encoded, key)
+	assert.Equal(t, nil, err)
+	_, err = other.Parse(encoded, key)
+	assert.Equal(t, ErrTokenBadIssuer, err)
+	_, err = Init(Settings{Issuer: "other", AcceptedIssuers: []string{"other", "accounts"}}).Parse(encoded, key)
+	assert.Equal(t, nil, err)
+	// Not checked without issuers
+	_, err = Init(Settings{}).Parse(encoded, key)
+	assert.Equal(t, nil, err)
+}
+
+func TestAudience(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	settings := Settings{
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+	}
+	auth := Init(settings)
+	settings.RequireAudience = true
+	strict := Init(settings)
+
+	request := func(service *serviceImpl, audience string, token *Token) (int, string) {
+		encoded, err := service.SignedStringForHttpRequest(token.Add("@scopes", "read"), nil)
+		assert.Equal(t, nil, err)
+		handler := service.RequiresAuthForAudience(audience, "read", nil, func(Context, http.ResponseWriter, *http.Request) {})
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+encoded)
+		resp := httptest.NewRecorder()
+		handler(resp, req)
+		return resp.Code, resp.Body.String()
+	}
+
+	code, _ := request(auth, "shorty", auth.NewToken().SetAudience("shorty"))
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = request(auth, "shorty", auth.NewToken().SetAudience("passport", "shorty"))
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = request(auth, "shorty", auth.NewToken())
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body := request(auth, "shorty", auth.NewToken().SetAudience("passport"))
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Tf(t, strings.Contains(body, ErrTokenBadAudience.Error()), "body=%s", body)
+	code, body = request(strict, "shorty", strict.NewToken())
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Tf(t, strings.Contains(body, ErrTokenNoAudience.Error()), "body=%s", body)
+
+	// Issued for, and then required by, the audience of the settings
+	settings.RequireAudience = false
+	settings.Audience = "shorty"
+	shorty := Init(settings)
+	assert.Equal(t, []string{"shorty"}, shorty.NewToken().Audience())
+	code, _ = request(shorty, "", shorty.NewToken())
+	assert.Equal(t, http.StatusOK, code)
+	code, body = request(shorty, "", auth.NewToken())
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Tf(t, strings.Contains(body, ErrTokenNoAudience.Error()), "body=%s", body)
+	settings.Audience = "passport"
+	code, _ = request(Init(settings), "", shorty.NewToken())
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	encoded, _ := auth.SignedStringForHttpRequest(auth.NewToken().SetAudience("a", "b"), nil)
+	parsed, err := auth.ParseForHttpRequest(encoded, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"a", "b"}, parsed.Audience())
+}
@@ -8,13 +8,45 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
-	"io"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Encryption is authenticated (AEAD), with AES-GCM or XChaCha20-Poly1305, and the encrypted
+// data is an envelope recording how it was encrypted:
+//
+//   0xae 0xad | version | algorithm | length of key id | key id | nonce | ciphertext and tag
+//
+// The header up to the nonce is authenticated along with the associated data given by the
+// caller, e.g. the id of the record holding the data, so that the data of one record cannot
+// be swapped for that of another.  The data encrypted before the envelopes, with AES-CFB, is
+// still decrypted but not authenticated; re-encrypt it, see Keyring.
+
+var (
+	ErrNoKeyring             = errors.New("no-keyring")
+	ErrUnknownCipher         = errors.New("unknown-cipher-algorithm")
+	ErrBadCipherKey          = errors.New("bad-cipher-key")
+	ErrBadCiphertext         = errors.New("bad-ciphertext")
+	ErrUnsupportedCiphertext = errors.New("unsupported-ciphertext-version")
+	ErrDecryptFailed         = errors.New("decrypt-failed")
+)
+
+const (
+	AESGCM            = "AES-GCM"
+	XChaCha20Poly1305 = "XChaCha20-Poly1305"
+
+	DefaultCipher = AESGCM
 )
 
 type CryptoService interface {
+	// With the key; the input may also be of the legacy encryption.
 	Encrypt(key, input []byte) (encrypted []byte, err error)
 	Decrypt(key, input []byte) (decrypted []byte, err error)
-	EncryptString(input string) (encrypted string, err error)
+	EncryptString(key []byte, input string) (encrypted string, err error)
+	DecryptString(key []byte, input string) (decrypted string, err error)
+	// With the keyring of the settings.
+	Seal(plaintext, data []byte) (encrypted []byte, err error)
+	Open(encrypted, data []byte) (plaintext []byte, err error)
+	Reencrypt(encrypted, data []byte) (reencrypted []byte, changed bool, err error)
 	HmacSha256(key, input []byte) (h []byte)
 	HmacSha256String(key []byte, input string) (h string)
 }
@@ -33,8 +65,13 @@ func (this *serviceImpl) HmacSha256String(key []byte, input string) (h string) {
 	return base64.StdEncoding.EncodeToString(buff)
 }
 
+// Encrypts with AES-GCM, the key being an AES key, without associated data.
 func (this *serviceImpl) Encrypt(key, input []byte) (encrypted []byte, err error) {
-	return encrypt(key, input)
+	aead, err := new_aes_gcm(key)
+	if err != nil {
+		return nil, err
+	}
+	return seal(cipher_algorithms[AESGCM], aead, "", input, nil)
 }
 
 func (this *serviceImpl) EncryptString(key []byte, input string) (encrypted string, err error) {
@@ -45,41 +82,212 @@ func (this *serviceImpl) EncryptString(key []byte, input string) (encrypted stri
 	return
 }
 
+// Decrypts the envelopes whatever their key id, and the legacy encryption.  Only the data
+// that is not a well formed envelope is taken for legacy data.
 func (this *serviceImpl) Decrypt(key, input []byte) (decrypted []byte, err error) {
-	return decrypt(key, input)
+	env, err := parse_envelope(input)
+	if err != nil {
+		// Legacy data may start like an envelope
+		legacy, legacy_err := decrypt_legacy(key, input)
+		switch {
+		case legacy_err == nil:
+			return legacy, nil
+		case !is_envelope(input):
+			return nil, legacy_err
+		}
+		return nil, err
+	}
+	aead, err := env.algorithm.new_aead(key)
+	if err != nil {
+		return nil, err
+	}
+	return env.open(aead, nil)
 }
 
-func encrypt(key, text []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
+func (this *serviceImpl) DecryptString(key []byte, input string) (decrypted string, err error) {
+	buff, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return "", ErrBadCiphertext
+	}
+	buff, err = this.Decrypt(key, buff)
+	return string(buff), err
+}
+
+func (this *serviceImpl) keyring() (*Keyring, error) {
+	if this.settings.Keyring == nil {
+		return nil, ErrNoKeyring
+	}
+	return this.settings.Keyring, nil
+}
+
+func (this *serviceImpl) Seal(plaintext, data []byte) ([]byte, error) {
+	keyring, err := this.keyring()
 	if err != nil {
 		return nil, err
 	}
-	b := base64.StdEncoding.EncodeToString(text)
-	ciphertext := make([]byte, aes.BlockSize+len(b))
-	iv := ciphertext[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+	return keyring.Encrypt(plaintext, data)
+}
+
+func (this *serviceImpl) Open(encrypted, data []byte) ([]byte, error) {
+	keyring, err := this.keyring()
+	if err != nil {
 		return nil, err
 	}
-	cfb := cipher.NewCFBEncrypter(block, iv)
-	cfb.XORKeyStream(ciphertext[aes.BlockSize:], []byte(b))
-	return ciphertext, nil
+	return keyring.Decrypt(encrypted, data)
+}
+
+func (this *serviceImpl) Reencrypt(encrypted, data []byte) ([]byte, bool, error) {
+	keyring, err := this.keyring()
+	if err != nil {
+		return nil, false, err
+	}
+	return keyring.Reencrypt(encrypted, data)
 }
 
-func decrypt(key, text []byte) ([]byte, error) {
+// The key size is that of the generated keys.
+type cipher_algorithm struct {
+	name       string
+	id         byte
+	new_aead   func(key []byte) (cipher.AEAD, error)
+	key_size   int
+	nonce_size int
+}
+
+var cipher_algorithms = map[string]*cipher_algorithm{
+	AESGCM: &cipher_algorithm{name: AESGCM, id: 1, new_aead: new_aes_gcm,
+		key_size: 32, nonce_size: 12},
+	XChaCha20Poly1305: &cipher_algorithm{name: XChaCha20Poly1305, id: 2, new_aead: new_xchacha20poly1305,
+		key_size: chacha20poly1305.KeySize, nonce_size: chacha20poly1305.NonceSizeX},
+}
+
+func cipher_algorithm_named(name string) (*cipher_algorithm, error) {
+	if name == "" {
+		name = DefaultCipher
+	}
+	if alg, has := cipher_algorithms[name]; has {
+		return alg, nil
+	}
+	return nil, ErrUnknownCipher
+}
+
+func cipher_algorithm_of(id byte) (*cipher_algorithm, error) {
+	for _, alg := range cipher_algorithms {
+		if alg.id == id {
+			return alg, nil
+		}
+	}
+	return nil, ErrUnknownCipher
+}
+
+// AES-128, 192 or 256 by the length of the key.
+func new_aes_gcm(key []byte) (cipher.AEAD, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
+		return nil, ErrBadCipherKey
+	}
+	return cipher.NewGCM(block)
+}
+
+func new_xchacha20poly1305(key []byte) (cipher.AEAD, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, ErrBadCipherKey
+	}
+	return aead, nil
+}
+
+const envelope_version = 1
+
+var envelope_magic = []byte{0xae, 0xad}
+
+type envelope struct {
+	algorithm *cipher_algorithm
+	key_id    string
+	header    []byte // up to and without the nonce
+	nonce     []byte
+	sealed    []byte
+}
+
+func is_envelope(buff []byte) bool {
+	return len(buff) > len(envelope_magic) && buff[0] == envelope_magic[0] && buff[1] == envelope_magic[1]
+}
+
+func envelope_header(alg *cipher_algorithm, key_id string) []byte {
+	header := append([]byte{}, envelope_magic...)
+	header = append(header, envelope_version, alg.id, byte(len(key_id)))
+	return append(header, key_id...)
+}
+
+// The header is authenticated along with the data.
+func envelope_data(header, data []byte) []byte {
+	return append(append([]byte{}, header...), data...)
+}
+
+func seal(alg *cipher_algorithm, aead cipher.AEAD, key_id string, plaintext, data []byte) ([]byte, error) {
+	if len(key_id) > 255 {
+		return nil, ErrBadCipherKey
+	}
+	header := envelope_header(alg, key_id)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
 		return nil, err
 	}
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plaintext, envelope_data(header, data)), nil
+}
+
+func parse_envelope(buff []byte) (*envelope, error) {
+	const fixed = 5 // magic, version, algorithm and key id length
+	if !is_envelope(buff) || len(buff) < fixed {
+		return nil, ErrBadCiphertext
+	}
+	if buff[2] != envelope_version {
+		return nil, ErrUnsupportedCiphertext
+	}
+	alg, err := cipher_algorithm_of(buff[3])
+	if err != nil {
+		return nil, err
+	}
+	end := fixed + int(buff[4])
+	if len(buff) < end {
+		return nil, ErrBadCiphertext
+	}
+	env := &envelope{algorithm: alg, key_id: string(buff[fixed:end]), header: buff[:end]}
+	if len(buff) < end+alg.nonce_size {
+		return nil, ErrBadCiphertext
+	}
+	env.nonce, env.sealed = buff[end:end+alg.nonce_size], buff[end+alg.nonce_size:]
+	return env, nil
+}
+
+func (this *envelope) open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(this.nonce) != aead.NonceSize() || len(this.sealed) < aead.Overhead() {
+		return nil, ErrBadCiphertext
+	}
+	plaintext, err := aead.Open(nil, this.nonce, this.sealed, envelope_data(this.header, data))
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
+
+// The legacy encryption: AES-CFB of the base64 encoding of the plaintext, with the iv as
+// prefix.  It is not authenticated, so a wrong key mostly fails decoding the base64.
+func decrypt_legacy(key, text []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrBadCipherKey
+	}
 	if len(text) < aes.BlockSize {
-		return nil, errors.New("ciphertext too short")
+		return nil, ErrBadCiphertext
 	}
 	iv := text[:aes.BlockSize]
-	text = text[aes.BlockSize:]
+	decrypted := make([]byte, len(text)-aes.BlockSize)
 	cfb := cipher.NewCFBDecrypter(block, iv)
-	cfb.XORKeyStream(text, text)
-	data, err := base64.StdEncoding.DecodeString(string(text))
+	cfb.XORKeyStream(decrypted, text[aes.BlockSize:])
+	data, err := base64.StdEncoding.DecodeString(string(decrypted))
 	if err != nil {
-		return nil, err
+		return nil, ErrDecryptFailed
 	}
 	return data, nil
 }
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+var _ CryptoService = &serviceImpl{}
+
+// The encryption before the envelopes.
+func encrypt_legacy(t *testing.T, key, text []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	rand.Read(iv)
+	return encrypt_legacy_iv(t, key, iv, text)
+}
+
+func encrypt_legacy_iv(t *testing.T, key, iv, text []byte) []byte {
+	block, err := aes.NewCipher(key)
+	assert.Equal(t, nil, err)
+	b := base64.StdEncoding.EncodeToString(text)
+	ciphertext := make([]byte, aes.BlockSize+len(b))
+	copy(ciphertext, iv)
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(ciphertext[aes.BlockSize:], []byte(b))
+	return ciphertext
+}
+
+func TestEncryptWithKey(t *testing.T) {
+	service := Init(Settings{})
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	encrypted, err := service.EncryptString(key, "tok_visa")
+	assert.Equal(t, nil, err)
+	decrypted, err := service.DecryptString(key, encrypted)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tok_visa", decrypted)
+
+	buff, _ := base64.StdEncoding.DecodeString(encrypted)
+	buff[len(buff)-1] ^= 1
+	_, err = service.Decrypt(key, buff)
+	assert.Equal(t, ErrDecryptFailed, err)
+
+	legacy := encrypt_legacy(t, key, []byte("cus_123"))
+	decoded, err := service.Decrypt(key, legacy)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "cus_123", string(decoded))
+
+	_, err = service.Encrypt([]byte("short"), []byte("x"))
+	assert.Equal(t, ErrBadCipherKey, err)
+}
+
+func TestKeyringAlgorithms(t *testing.T) {
+	for _, algorithm := range []string{AESGCM, XChaCha20Poly1305} {
+		key, err := GenerateCipherKey("k1", algorithm)
+		assert.Equal(t, nil, err)
+		keyring := NewKeyring()
+		assert.Equal(t, nil, keyring.Rotate(key))
+
+		encrypted, err := keyring.Encrypt([]byte("secret"), []byte("user:1"))
+		assert.Equal(t, nil, err)
+		env, err := parse_envelope(encrypted)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, algorithm, env.algorithm.name)
+		assert.Equal(t, "k1", env.key_id)
+
+		plaintext, err := keyring.Decrypt(encrypted, []byte("user:1"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "secret", string(plaintext))
+
+		// The data of another record
+		_, err = keyring.Decrypt(encrypted, []byte("user:2"))
+		assert.Equal(t, ErrDecryptFailed, err)
+
+		// The header is authenticated
+		tampered := append([]byte{}, encrypted...)
+		tampered[3] = 3 - tampered[3]
+		_, err = keyring.Decrypt(tampered, []byte("user:1"))
+		assert.NotEqual(t, nil, err)
+	}
+
+	assert.Equal(t, ErrUnknownCipher, NewKeyring().Add(CipherKey{Id: "k", Algorithm: "DES"}))
+	assert.Equal(t, ErrBadCipherKey, NewKeyring().Add(CipherKey{Id: "k", Algorithm: XChaCha20Poly1305, Secret: []byte("short")}))
+	_, err := NewKeyring().Encrypt([]byte("secret"), nil)
+	assert.Equal(t, ErrNoActiveKey, err)
+}
+
+func TestKeyringRotation(t *testing.T) {
+	legacy_key := []byte("0123456789abcdef")
+	k1, _ := GenerateCipherKey("k1", AESGCM)
+	k2, _ := GenerateCipherKey("k2", XChaCha20Poly1305)
+
+	keyring := NewKeyring()
+	keyring.LegacyKey = legacy_key
+	assert.Equal(t, nil, keyring.Rotate(k1))
+	service := Init(Settings{Keyring: keyring})
+
+	legacy := encrypt_legacy(t, legacy_key, []byte("twilio-token"))
+	old, err := service.Seal([]byte("cus_123"), []byte("customer:1"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, keyring.Current(old))
+	assert.Equal(t, false, keyring.Current(legacy))
+
+	assert.Equal(t, nil, keyring.Add(k2))
+	assert.Equal(t, ErrDuplicateKeyId, keyring.Add(k2))
+	assert.Equal(t, nil, keyring.Activate("k2"))
+	assert.Equal(t, false, keyring.Current(old))
+
+	// Both keys and the legacy key decrypt
+	for plaintext, encrypted := range map[string][]byte{"twilio-token": legacy, "cus_123": old} {
+		decrypted, err := service.Open(encrypted, []byte("customer:1"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, plaintext, string(decrypted))
+	}
+
+	// Re-encrypting the stored data with the active key
+	migrated, changed, err := service.Reencrypt(legacy, []byte("account:1"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, changed)
+	assert.Equal(t, true, keyring.Current(migrated))
+	current, changed, err := service.Reencrypt(old, []byte("customer:1"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, changed)
+	same, changed, err := service.Reencrypt(current, []byte("customer:1"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, changed)
+	assert.Equal(t, current, same)
+
+	// Retiring the old and the legacy keys
+	assert.Equal(t, ErrRemoveActiveKey, keyring.Remove("k2"))
+	assert.Equal(t, nil, keyring.Remove("k1"))
+	keyring.LegacyKey = nil
+	_, err = service.Open(old, []byte("customer:1"))
+	assert.Equal(t, ErrUnknownKeyId, err)
+	_, err = service.Open(legacy, nil)
+	assert.Equal(t, ErrBadCiphertext, err)
+	decrypted, err := service.Open(migrated, []byte("account:1"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "twilio-token", string(decrypted))
+
+	encrypted, err := keyring.EncryptString("cus_456", []byte("customer:2"))
+	assert.Equal(t, nil, err)
+	text, err := keyring.DecryptString(encrypted, []byte("customer:2"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "cus_456", text)
+
+	_, err = Init(Settings{}).Seal([]byte("x"), nil)
+	assert.Equal(t, ErrNoKeyring, err)
+}
+
+func TestKeyringEnvelopesNotLegacy(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	keyring := NewKeyring()
+	keyring.LegacyKey = key
+	assert.Equal(t, nil, keyring.Rotate(CipherKey{Id: "k1", Algorithm: AESGCM, Secret: key}))
+	service := Init(Settings{Keyring: keyring})
+
+	encrypted, err := keyring.Encrypt([]byte("cus_123"), []byte("customer:1"))
+	assert.Equal(t, nil, err)
+	_, err = keyring.Decrypt(encrypted, []byte("customer:2"))
+	assert.Equal(t, ErrDecryptFailed, err)
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = keyring.Decrypt(tampered, []byte("customer:1"))
+	assert.Equal(t, ErrDecryptFailed, err)
+
+	// Legacy data that is a well formed envelope as well, of the known key and of another
+	iv := append(envelope_header(cipher_algorithms[AESGCM], "k1"), make([]byte, 9)...)
+	legacy := encrypt_legacy_iv(t, key, iv[:aes.BlockSize], []byte("attacker-chosen plaintext"))
+	_, err = keyring.Decrypt(legacy, nil)
+	assert.Equal(t, ErrDecryptFailed, err)
+	_, err = service.Decrypt(key, legacy)
+	assert.Equal(t, ErrDecryptFailed, err)
+	iv = append(envelope_header(cipher_algorithms[AESGCM], "k9"), make([]byte, 9)...)
+	_, err = keyring.Decrypt(encrypt_legacy_iv(t, key, iv[:aes.BlockSize], []byte("attacker-chosen plaintext")), nil)
+	assert.Equal(t, ErrUnknownKeyId, err)
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"sort"
+	"sync"
+)
+
+// Key rotation: the data is encrypted with the active key of the keyring and its envelope
+// records the key id, so it is decrypted with the key that encrypted it.  To rotate, add
+// the new key, activate it once every instance has it, re-encrypt the stored data, e.g. the
+// stripe customer references and twilio tokens, and remove the old key once none of the
+// data is encrypted with it.  Data encrypted with the legacy key (AES-CFB, not authenticated)
+// is decrypted and re-encrypted the same way; unset the legacy key once it is all migrated.
+
+// The algorithm is DefaultCipher if empty.  The secret is the key of the algorithm, see
+// GenerateCipherKey.
+type CipherKey struct {
+	Id        string
+	Algorithm string
+	Secret    []byte
+
+	algorithm *cipher_algorithm
+	aead      cipher.AEAD
+}
+
+type Keyring struct {
+	// The AES key of the legacy encryption, if any of the data is still encrypted with it.
+	LegacyKey []byte
+
+	lock   sync.RWMutex
+	keys   map[string]*CipherKey
+	active string
+}
+
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]*CipherKey)}
+}
+
+// A random key of the algorithm.
+func GenerateCipherKey(id, algorithm string) (CipherKey, error) {
+	alg, err := cipher_algorithm_named(algorithm)
+	if err != nil {
+		return CipherKey{}, err
+	}
+	secret := make([]byte, alg.key_size)
+	if _, err := rand.Read(secret); err != nil {
+		return CipherKey{}, err
+	}
+	return CipherKey{Id: id, Algorithm: alg.name, Secret: secret}, nil
+}
+
+// Adds the key for decryption.  The key is not used for encryption until activated.
+func (this *Keyring) Add(key CipherKey) (err error) {
+	if key.Id == "" {
+		return ErrNoKeyId
+	}
+	if len(key.Id) > 255 {
+		return ErrBadCipherKey
+	}
+	if key.algorithm, err = cipher_algorithm_named(key.Algorithm); err != nil {
+		return
+	}
+	key.Algorithm = key.algorithm.name
+	if key.aead, err = key.algorithm.new_aead(key.Secret); err != nil {
+		return
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if _, has := this.keys[key.Id]; has {
+		return ErrDuplicateKeyId
+	}
+	this.keys[key.Id] = &key
+	return nil
+}
+
+// Encrypts the new data with the key.
+func (this *Keyring) Activate(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if _, has := this.keys[id]; !has {
+		return ErrUnknownKeyId
+	}
+	this.active = id
+	return nil
+}
+
+// Adds and activates the key.
+func (this *Keyring) Rotate(key CipherKey) error {
+	if err := this.Add(key); err != nil {
+		return err
+	}
+	return this.Activate(key.Id)
+}
+
+// Removes the key, so that the data it encrypted no longer decrypts.
+func (this *Keyring) Remove(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if id == this.active {
+		return ErrRemoveActiveKey
+	}
+	delete(this.keys, id)
+	return nil
+}
+
+// The active key, or nil.
+func (this *Keyring) Active() *CipherKey {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.keys[this.active]
+}
+
+// The key by id, or nil.
+func (this *Keyring) Get(id string) *CipherKey {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.keys[id]
+}
+
+// The ids of the keys, sorted.
+func (this *Keyring) Ids() []string {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	ids := make([]string, 0, len(this.keys))
+	for id, _ := range this.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Encrypts with the active key.  The data is authenticated, not encrypted, and must be
+// given again to decrypt.
+func (this *Keyring) Encrypt(plaintext, data []byte) ([]byte, error) {
+	key := this.Active()
+	if key == nil {
+		return nil, ErrNoActiveKey
+	}
+	return seal(key.algorithm, key.aead, key.Id, plaintext, data)
+}
+
+// Decrypts with the key of the envelope, or with the legacy key if not an envelope; the
+// data is ignored by the legacy encryption.
+func (this *Keyring) Decrypt(encrypted, data []byte) ([]byte, error) {
+	plaintext, _, err := this.decrypt(encrypted, data)
+	return plaintext, err
+}
+
+// The plaintext, and the key that decrypted it, nil for the legacy key.  Only the data that
+// is not a well formed envelope is taken for legacy data, so that the envelopes that fail
+// authentication, e.g. with the data of another record, are never decrypted without it.
+func (this *Keyring) decrypt(encrypted, data []byte) ([]byte, *CipherKey, error) {
+	env, err := parse_envelope(encrypted)
+	if err != nil {
+		return this.decrypt_legacy(encrypted, err)
+	}
+	key := this.Get(env.key_id)
+	if key == nil {
+		return nil, nil, ErrUnknownKeyId
+	}
+	if key.algorithm != env.algorithm {
+		return nil, nil, ErrDecryptFailed
+	}
+	plaintext, err := env.open(key.aead, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, key, nil
+}
+
+// Legacy data may start like an envelope: the error is that of the envelope if it is not
+// legacy data either.
+func (this *Keyring) decrypt_legacy(encrypted []byte, envelope_err error) ([]byte, *CipherKey, error) {
+	if this.LegacyKey == nil {
+		return nil, nil, envelope_err
+	}
+	plaintext, err := decrypt_legacy(this.LegacyKey, encrypted)
+	if err != nil {
+		if is_envelope(encrypted) {
+			return nil, nil, envelope_err
+		}
+		return nil, nil, err
+	}
+	return plaintext, nil, nil
+}
+
+// Whether the data is encrypted with the active key.
+func (this *Keyring) Current(encrypted []byte) bool {
+	env, err := parse_envelope(encrypted)
+	return err == nil && env.key_id != "" && env.key_id == this.active_id()
+}
+
+func (this *Keyring) active_id() string {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.active
+}
+
+// Decrypts and encrypts again with the active key, unless encrypted with it already.
+// Whether the encrypted data changed, and is to be stored, is returned.
+func (this *Keyring) Reencrypt(encrypted, data []byte) ([]byte, bool, error) {
+	plaintext, key, err := this.decrypt(encrypted, data)
+	if err != nil {
+		return nil, false, err
+	}
+	if key != nil && key == this.Active() {
+		return encrypted, false, nil
+	}
+	reencrypted, err := this.Encrypt(plaintext, data)
+	if err != nil {
+		return nil, false, err
+	}
+	return reencrypted, true, nil
+}
+
+// The encrypted data as base64, for the text columns.
+func (this *Keyring) EncryptString(plaintext string, data []byte) (string, error) {
+	encrypted, err := this.Encrypt([]byte(plaintext), data)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (this *Keyring) DecryptString(encrypted string, data []byte) (string, error) {
+	buff, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrBadCiphertext
+	}
+	plaintext, err := this.Decrypt(buff, data)
+	return string(plaintext), err
+}
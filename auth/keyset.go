@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Key rotation: the tokens are signed with the active key of the key set and stamped with
+// its id in the kid header, and verified with the key of their kid.  To rotate without
+// downtime, add the new key, activate it once every instance has it, and remove the old key
+// once the tokens it signed have expired.  The public keys are served as a JWKS document.
+
+var (
+	ErrNoKeyId         = errors.New("no-key-id")
+	ErrUnknownKeyId    = errors.New("unknown-key-id")
+	ErrDuplicateKeyId  = errors.New("duplicate-key-id")
+	ErrNoActiveKey     = errors.New("no-active-key")
+	ErrKeyCannotSign   = errors.New("key-cannot-sign")
+	ErrRemoveActiveKey = errors.New("cannot-remove-active-key")
+)
+
+// The signing method is DefaultSigningMethod if empty.  The sign key is the secret or the
+// PEM private key, and can be nil for keys only verifying, e.g. the keys of other services.
+// The verify key is the secret or the PEM public key or certificate, derived from the
+// private key if nil.
+type Key struct {
+	Id            string
+	SigningMethod string
+	SignKey       []byte
+	VerifyKey     []byte
+
+	sign   interface{}
+	verify interface{}
+}
+
+type KeySet struct {
+	lock   sync.RWMutex
+	keys   map[string]*Key
+	active string
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*Key)}
+}
+
+// Adds the key for verification.  The key is not used for signing until activated.
+func (this *KeySet) Add(key Key) error {
+	if key.Id == "" {
+		return ErrNoKeyId
+	}
+	if key.SigningMethod == "" {
+		key.SigningMethod = DefaultSigningMethod
+	}
+	if _, err := signing_method(key.SigningMethod); err != nil {
+		return err
+	}
+	if err := key.parse(); err != nil {
+		return err
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if _, has := this.keys[key.Id]; has {
+		return ErrDuplicateKeyId
+	}
+	this.keys[key.Id] = &key
+	return nil
+}
+
+func (this *Key) parse() (err error) {
+	if this.SignKey != nil {
+		if this.sign, err = signing_key(this.SigningMethod, this.SignKey); err != nil {
+			return
+		}
+	}
+	if this.VerifyKey == nil && this.sign != nil {
+		switch private := this.sign.(type) {
+		case []byte:
+			this.VerifyKey = private
+		case interface{ Public() crypto.PublicKey }:
+			der, err := x509.MarshalPKIXPublicKey(private.Public())
+			if err != nil {
+				return err
+			}
+			this.VerifyKey = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		}
+	}
+	if this.VerifyKey == nil {
+		return ErrNoVerifyKey
+	}
+	this.verify, err = verification_key(this.SigningMethod, this.VerifyKey)
+	return
+}
+
+// Signs the new tokens with the key.
+func (this *KeySet) Activate(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	key, has := this.keys[id]
+	if !has {
+		return ErrUnknownKeyId
+	}
+	if key.sign == nil {
+		return ErrKeyCannotSign
+	}
+	this.active = id
+	return nil
+}
+
+// Adds and activates the key.
+func (this *KeySet) Rotate(key Key) error {
+	if err := this.Add(key); err != nil {
+		return err
+	}
+	return this.Activate(key.Id)
+}
+
+// Removes the key, so that the tokens it signed no longer verify.
+func (this *KeySet) Remove(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if id == this.active {
+		return ErrRemoveActiveKey
+	}
+	delete(this.keys, id)
+	return nil
+}
+
+// The active key, or nil.
+func (this *KeySet) Active() *Key {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.keys[this.active]
+}
+
+// The key by id, or nil.
+func (this *KeySet) Get(id string) *Key {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.keys[id]
+}
+
+// The ids of the keys, sorted.
+func (this *KeySet) Ids() []string {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	ids := make([]string, 0, len(this.keys))
+	for id, _ := range this.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (this *KeySet) sign(token *Token) (string, error) {
+	key := this.Active()
+	if key == nil {
+		return "", ErrNoActiveKey
+	}
+	method, _ := signing_method(key.SigningMethod)
+	token.token.Method = method
+	token.token.Header["alg"] = method.Alg()
+	token.token.Header["kid"] = key.Id
+	return token.token.SignedString(key.sign)
+}
+
+// The key function verifying the tokens by their kid.
+func (this *KeySet) keyfunc(t *jwt.Token) (interface{}, error) {
+	id, _ := t.Header["kid"].(string)
+	if id == "" {
+		return nil, ErrNoKeyId
+	}
+	key := this.Get(id)
+	if key == nil {
+		return nil, ErrUnknownKeyId
+	}
+	if t.Method.Alg() != key.SigningMethod {
+		return nil, ErrUnexpectedSigningMethod
+	}
+	return key.verify, nil
+}
+
+// A JSON Web Key (RFC 7517) of a public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// The public keys of the set.  The HMAC secrets are never published.
+func (this *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range this.Ids() {
+		key := this.Get(id)
+		if key == nil {
+			continue
+		}
+		jwk := JWK{Kid: key.Id, Use: "sig", Alg: key.SigningMethod}
+		switch public := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64_url(public.N.Bytes())
+			jwk.E = base64_url(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = public.Curve.Params().Name
+			jwk.X = base64_url(padded(public.X.Bytes(), size))
+			jwk.Y = base64_url(padded(public.Y.Bytes(), size))
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func base64_url(buff []byte) string {
+	return base64.RawURLEncoding.EncodeToString(buff)
+}
+
+func padded(buff []byte, size int) []byte {
+	if len(buff) >= size {
+		return buff
+	}
+	result := make([]byte, size)
+	copy(result[size-len(buff):], buff)
+	return result
+}
+
+// Serves the JWKS document of the public keys.
+func (this *KeySet) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Cache-Control", "max-age=300")
+	json.NewEncoder(resp).Encode(this.JWKS())
+}
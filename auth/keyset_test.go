@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyRotation(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaSign, _ := pem_keys(t, rsaKey)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSign, _ := pem_keys(t, ecKey)
+
+	keys := NewKeySet()
+	assert.Equal(t, nil, keys.Rotate(Key{Id: "k1", SigningMethod: RS256, SignKey: rsaSign}))
+	legacy := func(*http.Request) []byte { return []byte("legacy") }
+	auth := Init(Settings{KeySet: keys, VerifyKeyFromHttpRequest: legacy})
+	req, _ := http.NewRequest("GET", "/", nil)
+
+	old, err := auth.SignedStringForHttpRequest(auth.NewToken().Add("n", "1"), req)
+	assert.Equal(t, nil, err)
+
+	// The new key is rolled out, then activated
+	assert.Equal(t, nil, keys.Add(Key{Id: "k2", SigningMethod: ES256, SignKey: ecSign}))
+	assert.Equal(t, ErrDuplicateKeyId, keys.Add(Key{Id: "k2", SignKey: []byte("x")}))
+	assert.Equal(t, nil, keys.Activate("k2"))
+	current, err := auth.SignedStringForHttpRequest(auth.NewToken().Add("n", "2"), req)
+	assert.Equal(t, nil, err)
+
+	for n, encoded := range map[string]string{"1": old, "2": current} {
+		token, err := auth.ParseForHttpRequest(encoded, req)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, n, token.GetString("n"))
+	}
+	token, _ := auth.ParseForHttpRequest(current, req)
+	assert.Equal(t, "k2", token.token.Header["kid"])
+	assert.Equal(t, ES256, token.token.Header["alg"])
+
+	// The tokens signed before the key set
+	hs := Init(Settings{})
+	signed, _ := hs.SignedString(hs.NewToken().Add("n", "0"), func() []byte { return []byte("legacy") })
+	token, err = auth.ParseForHttpRequest(signed, req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "0", token.GetString("n"))
+
+	// Retiring the old key
+	assert.Equal(t, ErrRemoveActiveKey, keys.Remove("k2"))
+	assert.Equal(t, nil, keys.Remove("k1"))
+	_, err = auth.ParseForHttpRequest(old, req)
+	assert.Equal(t, ErrUnknownKeyId.Error(), err.Error())
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, rsaVerify := pem_keys(t, rsaKey)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSign, _ := pem_keys(t, ecKey)
+
+	keys := NewKeySet()
+	assert.Equal(t, nil, keys.Add(Key{Id: "rsa", SigningMethod: RS256, VerifyKey: rsaVerify}))
+	assert.Equal(t, ErrKeyCannotSign, keys.Activate("rsa"))
+	assert.Equal(t, nil, keys.Rotate(Key{Id: "ec", SigningMethod: ES256, SignKey: ecSign}))
+	assert.Equal(t, nil, keys.Add(Key{Id: "secret", SignKey: []byte("secret")}))
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	keys.ServeHTTP(resp, req)
+
+	jwks := JWKS{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &jwks))
+	assert.Equal(t, 2, len(jwks.Keys))
+	assert.Equal(t, "ec", jwks.Keys[0].Kid)
+	assert.Equal(t, "EC", jwks.Keys[0].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[0].Crv)
+	assert.Equal(t, 43, len(jwks.Keys[0].X))
+	assert.Equal(t, "rsa", jwks.Keys[1].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+}
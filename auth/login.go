@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+)
+
+// Implemented by the services issuing tokens for the logins of the accounts.
+type LoginService interface {
+	NewTokenForLogin(account, password string, req *http.Request) (*Token, error)
+}
+
+// A new token for the account once its password is checked against the credentials of the
+// settings, with the passwords and the login throttle of the settings if any.  The account
+// is the subject of the token.
+func (this *serviceImpl) NewTokenForLogin(account, password string, req *http.Request) (*Token, error) {
+	if this.settings.Credentials == nil {
+		return nil, ErrUnknownAccount
+	}
+	err := Login(this.settings.Credentials, this.settings.Passwords, this.settings.LoginThrottle, account, password, req)
+	if err != nil {
+		return nil, err
+	}
+	return this.NewToken().Add(SubjectClaim, account), nil
+}
+
+// Checks the password of the account in the store with the passwords, DefaultPasswords if
+// nil, and the login throttle if not nil, which counts the invalid credentials.
+func Login(store CredentialStore, passwords *Passwords, throttle *LoginThrottle, account, password string,
+	req *http.Request) error {
+	var attempt *LoginAttempt
+	if throttle != nil {
+		var wait time.Duration
+		var err error
+		if attempt, wait, err = throttle.Attempt(account, req); err != nil {
+			logger.ForRequest(req).Warning("login-throttled", "account", account, "wait", wait)
+			return err
+		}
+	}
+	if passwords == nil {
+		passwords = DefaultPasswords
+	}
+	err := passwords.Login(store, account, password)
+	switch {
+	case attempt == nil, err == ErrInvalidCredentials:
+	case err == nil:
+		attempt.Succeeded()
+	default:
+		attempt.Cancel()
+	}
+	return err
+}
@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+	"strings"
+	"sync"
+)
+
+// Password hashes carry their algorithm and parameters: the PHC strings
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key> and $scrypt$ln=15,r=8,p=1$<salt>$<key>, and
+// the bcrypt strings $2a$<cost>$<salt and key>.  The hashes of any of the algorithms are
+// verified; those not of the hasher of the passwords, or with other parameters, are to be
+// rehashed once the password is verified.
+
+var (
+	ErrBadPasswordHash      = errors.New("bad-password-hash")
+	ErrUnknownHashAlgorithm = errors.New("unknown-password-hash-algorithm")
+	ErrUnknownAccount       = errors.New("unknown-account")
+	ErrInvalidCredentials   = errors.New("invalid-credentials")
+)
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Whether the password matches the hash, which may have any parameters.
+	Verify(encoded, password string) (bool, error)
+	// Whether the hash has the parameters of the hasher.
+	Current(encoded string) bool
+}
+
+type Argon2id struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+	KeyLen  uint32
+}
+
+// N is 1<<LogN.
+type Scrypt struct {
+	LogN   uint8
+	R      int
+	P      int
+	KeyLen int
+}
+
+type Bcrypt struct {
+	Cost int
+}
+
+var (
+	DefaultArgon2id = &Argon2id{Time: 3, Memory: 64 * 1024, Threads: 2, KeyLen: 32}
+	DefaultScrypt   = &Scrypt{LogN: 15, R: 8, P: 1, KeyLen: 32}
+	DefaultBcrypt   = &Bcrypt{Cost: 12}
+
+	DefaultPasswords = &Passwords{Hasher: DefaultArgon2id}
+)
+
+const password_salt_len = 16
+
+func password_salt() ([]byte, error) {
+	salt := make([]byte, password_salt_len)
+	_, err := rand.Read(salt)
+	return salt, err
+}
+
+func b64(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// The parameters, salt and key of a PHC string of the algorithm.
+func parse_phc(encoded, algorithm string) (params string, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	// The version is optional
+	if len(parts) == 6 && parts[1] == algorithm {
+		parts = append(parts[:2], parts[3:]...)
+	}
+	if len(parts) != 5 || parts[0] != "" || parts[1] != algorithm {
+		return "", nil, nil, ErrBadPasswordHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return "", nil, nil, ErrBadPasswordHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil || len(key) == 0 {
+		return "", nil, nil, ErrBadPasswordHash
+	}
+	return parts[2], salt, key, nil
+}
+
+func (this *Argon2id) encode(salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		this.Memory, this.Time, this.Threads, b64(salt), b64(key))
+}
+
+func (this *Argon2id) Hash(password string) (string, error) {
+	salt, err := password_salt()
+	if err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, this.Time, this.Memory, this.Threads, this.KeyLen)
+	return this.encode(salt, key), nil
+}
+
+func parse_argon2id(encoded string) (*Argon2id, []byte, []byte, error) {
+	if !strings.HasPrefix(encoded, fmt.Sprintf("$argon2id$v=%d$", argon2.Version)) {
+		return nil, nil, nil, ErrBadPasswordHash
+	}
+	params, salt, key, err := parse_phc(encoded, "argon2id")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p := &Argon2id{KeyLen: uint32(len(key))}
+	if _, err := fmt.Sscanf(params, "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return nil, nil, nil, ErrBadPasswordHash
+	}
+	if p.Time == 0 || p.Threads == 0 || p.Memory < 8*uint32(p.Threads) {
+		return nil, nil, nil, ErrBadPasswordHash
+	}
+	return p, salt, key, nil
+}
+
+func (this *Argon2id) Verify(encoded, password string) (bool, error) {
+	p, salt, key, err := parse_argon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	computed := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+	return subtle.ConstantTimeCompare(computed, key) == 1, nil
+}
+
+func (this *Argon2id) Current(encoded string) bool {
+	p, salt, _, err := parse_argon2id(encoded)
+	return err == nil && *p == *this && len(salt) >= password_salt_len
+}
+
+func (this *Scrypt) encode(salt, key []byte) string {
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", this.LogN, this.R, this.P, b64(salt), b64(key))
+}
+
+func (this *Scrypt) Hash(password string) (string, error) {
+	salt, err := password_salt()
+	if err != nil {
+		return "", err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<this.LogN, this.R, this.P, this.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return this.encode(salt, key), nil
+}
+
+func parse_scrypt(encoded string) (*Scrypt, []byte, []byte, error) {
+	params, salt, key, err := parse_phc(encoded, "scrypt")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p := &Scrypt{KeyLen: len(key)}
+	if _, err := fmt.Sscanf(params, "ln=%d,r=%d,p=%d", &p.LogN, &p.R, &p.P); err != nil {
+		return nil, nil, nil, ErrBadPasswordHash
+	}
+	if p.LogN < 1 || p.LogN > 30 {
+		return nil, nil, nil, ErrBadPasswordHash
+	}
+	return p, salt, key, nil
+}
+
+func (this *Scrypt) Verify(encoded, password string) (bool, error) {
+	p, salt, key, err := parse_scrypt(encoded)
+	if err != nil {
+		return false, err
+	}
+	computed, err := scrypt.Key([]byte(password), salt, 1<<p.LogN, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return false, ErrBadPasswordHash
+	}
+	return subtle.ConstantTimeCompare(computed, key) == 1, nil
+}
+
+func (this *Scrypt) Current(encoded string) bool {
+	p, salt, _, err := parse_scrypt(encoded)
+	return err == nil && *p == *this && len(salt) >= password_salt_len
+}
+
+func (this *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), this.Cost)
+	return string(hash), err
+}
+
+func (this *Bcrypt) Verify(encoded, password string) (bool, error) {
+	switch err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	default:
+		return false, ErrBadPasswordHash
+	}
+}
+
+func (this *Bcrypt) Current(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err == nil && cost == this.Cost
+}
+
+// The hasher verifying the hash, by its algorithm.
+func password_verifier(encoded string) (PasswordHasher, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return DefaultArgon2id, nil
+	case strings.HasPrefix(encoded, "$scrypt$"):
+		return DefaultScrypt, nil
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return DefaultBcrypt, nil
+	}
+	return nil, ErrUnknownHashAlgorithm
+}
+
+// Hashes with the hasher, argon2id by default, and verifies the hashes of any algorithm.
+type Passwords struct {
+	Hasher PasswordHasher
+
+	dummy      string
+	dummy_once sync.Once
+}
+
+func (this *Passwords) hasher() PasswordHasher {
+	if this.Hasher == nil {
+		return DefaultArgon2id
+	}
+	return this.Hasher
+}
+
+func (this *Passwords) Hash(password string) (string, error) {
+	return this.hasher().Hash(password)
+}
+
+// Whether the password matches, and whether the hash should then be replaced by a new hash
+// of the password, with the hasher and its parameters.
+func (this *Passwords) Verify(encoded, password string) (ok, rehash bool, err error) {
+	verifier, err := password_verifier(encoded)
+	if err != nil {
+		return false, false, err
+	}
+	if ok, err = verifier.Verify(encoded, password); err != nil || !ok {
+		return false, false, err
+	}
+	return true, !this.hasher().Current(encoded), nil
+}
+
+// Takes as long as verifying a password, for the unknown accounts.
+func (this *Passwords) verify_dummy(password string) {
+	this.dummy_once.Do(func() { this.dummy, _ = this.Hash("dummy") })
+	this.Verify(this.dummy, password)
+}
+
+// The password hashes of the accounts.
+type CredentialStore interface {
+	// The hash of the password of the account, or ErrUnknownAccount.
+	PasswordHash(account string) (string, error)
+	SetPasswordHash(account, hash string) error
+}
+
+// Checks the password of the account, ErrInvalidCredentials if it does not match or the
+// account is unknown.  The hash is replaced if outdated; failing to do so is only logged.
+func (this *Passwords) Login(store CredentialStore, account, password string) error {
+	encoded, err := store.PasswordHash(account)
+	switch {
+	case err == ErrUnknownAccount:
+		this.verify_dummy(password)
+		return ErrInvalidCredentials
+	case err != nil:
+		return err
+	}
+	ok, rehash, err := this.Verify(encoded, password)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return ErrInvalidCredentials
+	case rehash:
+		hash, err := this.Hash(password)
+		if err == nil {
+			err = store.SetPasswordHash(account, hash)
+		}
+		if err != nil {
+			logger.Warning("rehash-password-error", "account", account, "error", err)
+		}
+	}
+	return nil
+}
+
+// With the default passwords.
+func HashPassword(password string) (string, error) {
+	return DefaultPasswords.Hash(password)
+}
+
+func VerifyPassword(encoded, password string) (ok, rehash bool, err error) {
+	return DefaultPasswords.Verify(encoded, password)
+}
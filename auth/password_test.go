@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	test_argon2id = &Argon2id{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32}
+	test_scrypt   = &Scrypt{LogN: 10, R: 8, P: 1, KeyLen: 32}
+	test_bcrypt   = &Bcrypt{Cost: 4}
+)
+
+type memory_credentials map[string]string
+
+func (this memory_credentials) PasswordHash(account string) (string, error) {
+	if hash, has := this[account]; has {
+		return hash, nil
+	}
+	return "", ErrUnknownAccount
+}
+
+func (this memory_credentials) SetPasswordHash(account, hash string) error {
+	this[account] = hash
+	return nil
+}
+
+func TestPasswordHashers(t *testing.T) {
+	for _, hasher := range []PasswordHasher{test_argon2id, test_scrypt, test_bcrypt} {
+		hash, err := hasher.Hash("secret")
+		assert.Equal(t, nil, err)
+		ok, err := hasher.Verify(hash, "secret")
+		assert.Equal(t, nil, err)
+		assert.Tf(t, ok, "hash=%s", hash)
+		ok, _ = hasher.Verify(hash, "Secret")
+		assert.Tf(t, !ok, "hash=%s", hash)
+		assert.Tf(t, hasher.Current(hash), "hash=%s", hash)
+
+		other, _ := hasher.Hash("secret")
+		assert.NotEqual(t, hash, other)
+	}
+
+	hash, _ := test_argon2id.Hash("secret")
+	assert.Tf(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), "hash=%s", hash)
+	hash, _ = test_scrypt.Hash("secret")
+	assert.Tf(t, strings.HasPrefix(hash, "$scrypt$ln=10,r=8,p=1$"), "hash=%s", hash)
+
+	_, err := test_argon2id.Verify("$argon2id$v=19$m=1024$c2FsdA$a2V5", "secret")
+	assert.Equal(t, ErrBadPasswordHash, err)
+	_, _, err = VerifyPassword("plain", "plain")
+	assert.Equal(t, ErrUnknownHashAlgorithm, err)
+}
+
+func TestPasswordRehash(t *testing.T) {
+	passwords := &Passwords{Hasher: test_argon2id}
+	for _, hasher := range []PasswordHasher{test_scrypt, test_bcrypt, &Argon2id{Time: 1, Memory: 512, Threads: 1, KeyLen: 32}} {
+		hash, _ := hasher.Hash("secret")
+		ok, rehash, err := passwords.Verify(hash, "secret")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, true, rehash)
+	}
+	hash, _ := passwords.Hash("secret")
+	_, rehash, _ := passwords.Verify(hash, "secret")
+	assert.Equal(t, false, rehash)
+
+	// Rehashed on login
+	old, _ := test_bcrypt.Hash("secret")
+	credentials := memory_credentials{"joe": old}
+	assert.Equal(t, ErrInvalidCredentials, passwords.Login(credentials, "joe", "wrong"))
+	assert.Equal(t, old, credentials["joe"])
+	assert.Equal(t, nil, passwords.Login(credentials, "joe", "secret"))
+	assert.Tf(t, strings.HasPrefix(credentials["joe"], "$argon2id$"), "hash=%s", credentials["joe"])
+	assert.Equal(t, nil, passwords.Login(credentials, "joe", "secret"))
+	assert.Equal(t, ErrInvalidCredentials, passwords.Login(credentials, "jane", "secret"))
+}
+
+func throttle_request(remote, forwarded string) *http.Request {
+	r, _ := http.NewRequest("POST", "/login", nil)
+	r.RemoteAddr = remote + ":4321"
+	if forwarded != "" {
+		r.Header.Set("X-Forwarded-For", forwarded)
+	}
+	return r
+}
+
+func TestLoginThrottle(t *testing.T) {
+	throttle := NewLoginThrottle()
+	throttle.MaxPerAccount, throttle.MaxPerIp = 2, 3
+	req := func(ip string) *http.Request { return throttle_request(ip, "") }
+
+	_, _, err := throttle.Attempt("joe", req("10.0.0.1"))
+	assert.Equal(t, nil, err)
+	throttle.Attempt("joe", req("10.0.0.2"))
+	wait, err := attempt_error(throttle, "joe", req("10.0.0.3"))
+	assert.Equal(t, ErrLoginThrottled, err)
+	assert.Tf(t, wait > 14*time.Minute && wait <= DefaultLoginWindow, "wait=%s", wait)
+
+	// Per ip, across accounts
+	throttle.Attempt("jane", req("10.0.0.1"))
+	throttle.Attempt("jim", req("10.0.0.1"))
+	_, err = attempt_error(throttle, "ann", req("10.0.0.1"))
+	assert.Equal(t, ErrLoginThrottled, err)
+	ann, _, err := throttle.Attempt("ann", req("10.0.0.4"))
+	assert.Equal(t, nil, err)
+	ann.Succeeded()
+
+	// Cancelled attempts do not count
+	for i := 0; i < 3; i++ {
+		attempt, _, err := throttle.Attempt("bob", req("10.0.0.5"))
+		assert.Equal(t, nil, err)
+		attempt.Cancel()
+	}
+}
+
+func attempt_error(throttle *LoginThrottle, account string, req *http.Request) (time.Duration, error) {
+	_, wait, err := throttle.Attempt(account, req)
+	return wait, err
+}
+
+func TestLoginThrottleProxies(t *testing.T) {
+	throttle := NewLoginThrottle()
+	throttle.MaxPerAccount, throttle.MaxPerIp = 100, 2
+
+	// Forwarded addresses of untrusted clients are ignored
+	for _, spoofed := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		throttle.Attempt("joe", throttle_request("10.0.0.1", spoofed))
+	}
+	_, err := attempt_error(throttle, "jane", throttle_request("10.0.0.1", "4.4.4.4"))
+	assert.Equal(t, ErrLoginThrottled, err)
+
+	// Behind the trusted proxies, the nearest untrusted address is the client's
+	throttle.TrustedProxies = []string{"192.168.0.0/16", "172.16.0.1"}
+	throttle.Attempt("joe", throttle_request("192.168.1.1", "9.9.9.9, 5.5.5.5, 172.16.0.1"))
+	throttle.Attempt("joe", throttle_request("192.168.1.2", "5.5.5.5"))
+	_, err = attempt_error(throttle, "jane", throttle_request("192.168.1.1", "6.6.6.6, 5.5.5.5"))
+	assert.Equal(t, ErrLoginThrottled, err)
+	_, err = attempt_error(throttle, "jane", throttle_request("192.168.1.1", "6.6.6.6"))
+	assert.Equal(t, nil, err)
+}
+
+func TestLoginThrottleConcurrent(t *testing.T) {
+	passwords := &Passwords{Hasher: test_argon2id}
+	hash, _ := passwords.Hash("secret")
+	throttle := NewLoginThrottle()
+	throttle.MaxPerAccount = 3
+	credentials := memory_credentials{"joe": hash}
+
+	results := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		go func() {
+			results <- Login(credentials, passwords, throttle, "joe", "wrong", throttle_request("10.0.0.1", ""))
+		}()
+	}
+	invalid := 0
+	for i := 0; i < 20; i++ {
+		if <-results == ErrInvalidCredentials {
+			invalid++
+		}
+	}
+	assert.Equal(t, 3, invalid)
+}
+
+func TestNewTokenForLogin(t *testing.T) {
+	passwords := &Passwords{Hasher: test_argon2id}
+	hash, _ := passwords.Hash("secret")
+	throttle := NewLoginThrottle()
+	throttle.MaxPerAccount = 1
+	auth := Init(Settings{
+		Credentials:   memory_credentials{"joe": hash},
+		Passwords:     passwords,
+		LoginThrottle: throttle,
+	})
+	req, _ := http.NewRequest("POST", "/login", nil)
+
+	token, err := auth.NewTokenForLogin("joe", "secret", req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "joe", token.GetString(SubjectClaim))
+	assert.NotEqual(t, "", token.GetString(JtiClaim))
+
+	_, err = auth.NewTokenForLogin("joe", "wrong", req)
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, err = auth.NewTokenForLogin("joe", "secret", req)
+	assert.Equal(t, ErrLoginThrottled, err)
+
+	_, err = Init(Settings{}).NewTokenForLogin("joe", "secret", req)
+	assert.Equal(t, ErrUnknownAccount, err)
+}
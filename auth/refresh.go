@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Refresh tokens are long-lived tokens of type refresh, exchanged once for a new access
+// token and a new refresh token of the same family.  Each refresh token is revoked when
+// exchanged; presenting it again means it leaked, and revokes the whole family.
+
+const (
+	TokenTypeClaim   = "@type"
+	TokenTypeRefresh = "refresh"
+	TokenTypeCode    = "code"
+
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrNotRefreshToken     = errors.New("not-refresh-token")
+	ErrRefreshTokenReused  = errors.New("refresh-token-reused")
+	ErrRefreshTokenAsToken = errors.New("refresh-token-not-access-token")
+	ErrNotAccessToken      = errors.New("not-access-token")
+)
+
+// Implemented by the services issuing refresh tokens.
+type RefreshService interface {
+	IssueForHttpRequest(token *Token, req *http.Request) (access, refresh string, err error)
+	RefreshForHttpRequest(refreshString string, req *http.Request) (access, refresh string, err error)
+}
+
+// The claims of the access tokens not carried over to the tokens of the refresh flow.
+var refresh_reserved_claims = map[string]bool{
+	"exp": true, "nbf": true, "iat": true, "iss": true, JtiClaim: true, TokenTypeClaim: true,
+}
+
+func (this *serviceImpl) refresh_ttl() time.Duration {
+	if this.settings.RefreshTTLHours > 0 {
+		return time.Hour * this.settings.RefreshTTLHours
+	}
+	return DefaultRefreshTTL
+}
+
+func (this *Token) IsRefreshToken() bool {
+	return this.GetString(TokenTypeClaim) == TokenTypeRefresh
+}
+
+// Access tokens have no type; typed tokens like refresh tokens and authorization codes are
+// only exchanged for access tokens.
+func (this *Token) IsAccessToken() bool {
+	return !this.HasKey(TokenTypeClaim)
+}
+
+// The refresh token with the claims of the token, in the family of the token.
+func (this *serviceImpl) new_refresh_token(token *Token) *Token {
+	refresh := this.NewToken()
+	for key, value := range token.token.Claims {
+		if !refresh_reserved_claims[key] {
+			refresh.Add(key, value)
+		}
+	}
+	refresh.Add(TokenTypeClaim, TokenTypeRefresh)
+	delete(refresh.token.Claims, "exp")
+	refresh.SetExpiration(this.refresh_ttl())
+	return refresh
+}
+
+// Signs the access token and issues its refresh token, starting a new family, e.g. on login.
+func (this *serviceImpl) IssueForHttpRequest(token *Token, req *http.Request) (access, refresh string, err error) {
+	if this.settings.RevocationStore == nil {
+		return "", "", ErrNoRevocationStore
+	}
+	token.Add(FamilyClaim, new_token_id())
+	if access, err = this.SignedStringForHttpRequest(token, req); err != nil {
+		return "", "", err
+	}
+	refresh, err = this.SignedStringForHttpRequest(this.new_refresh_token(token), req)
+	return
+}
+
+// Exchanges the refresh token for a new access token and a new refresh token with the same
+// claims.  The refresh token can be exchanged only once.
+func (this *serviceImpl) RefreshForHttpRequest(refreshString string, req *http.Request) (access, refresh string, err error) {
+	store := this.settings.RevocationStore
+	if store == nil {
+		return "", "", ErrNoRevocationStore
+	}
+	token, err := this.ParseForHttpRequest(refreshString, req)
+	if err != nil {
+		return "", "", err
+	}
+	if !token.IsRefreshToken() {
+		return "", "", ErrNotRefreshToken
+	}
+	if family := token.GetString(FamilyClaim); family != "" {
+		revoked, err := store.IsRevoked(family_id(family))
+		if err != nil {
+			return "", "", err
+		}
+		if revoked {
+			return "", "", ErrRevokedToken
+		}
+	}
+	// The token itself is revoked once exchanged, see below
+	jti := token.GetString(JtiClaim)
+	if jti == "" {
+		return "", "", ErrNoTokenId
+	}
+	first, err := store.Revoke(jti, this.revoked_until(token))
+	if err != nil {
+		return "", "", err
+	}
+	if !first {
+		logger.ForRequest(req).Warning("refresh-token-reused", "family", token.GetString(FamilyClaim))
+		if err = this.RevokeFamily(token); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrRefreshTokenReused
+	}
+
+	next := this.NewToken()
+	for key, value := range token.token.Claims {
+		if !refresh_reserved_claims[key] {
+			next.Add(key, value)
+		}
+	}
+	if access, err = this.SignedStringForHttpRequest(next, req); err != nil {
+		return "", "", err
+	}
+	refresh, err = this.SignedStringForHttpRequest(this.new_refresh_token(next), req)
+	return
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func refresh_service() *serviceImpl {
+	key := func(*http.Request) []byte { return []byte("test") }
+	return Init(Settings{
+		TTLHours:                 1,
+		RevocationStore:          NewMemoryRevocationStore(),
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+	})
+}
+
+func authorized(auth *serviceImpl, token string) int {
+	handler := auth.RequiresAuth("read", nil, func(Context, http.ResponseWriter, *http.Request) {})
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	handler(resp, req)
+	return resp.Code
+}
+
+func TestRefresh(t *testing.T) {
+	auth := refresh_service()
+	req, _ := http.NewRequest("POST", "/token", nil)
+
+	access, refresh, err := auth.IssueForHttpRequest(auth.NewToken().Add("@scopes", "read"), req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusOK, authorized(auth, access))
+	// The refresh token is not an access token
+	assert.Equal(t, http.StatusUnauthorized, authorized(auth, refresh))
+
+	access2, refresh2, err := auth.RefreshForHttpRequest(refresh, req)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, http.StatusOK, authorized(auth, access2))
+	parsed, _ := auth.ParseForHttpRequest(access2, req)
+	assert.Equal(t, "read", parsed.GetString("@scopes"))
+	assert.NotEqual(t, "", parsed.GetString(JtiClaim))
+
+	_, _, err = auth.RefreshForHttpRequest(access2, req)
+	assert.NotEqual(t, nil, err)
+
+	// Reusing the first refresh token revokes the family
+	_, _, err = auth.RefreshForHttpRequest(refresh, req)
+	assert.Equal(t, ErrRefreshTokenReused, err)
+	_, _, err = auth.RefreshForHttpRequest(refresh2, req)
+	assert.Equal(t, ErrRevokedToken, err)
+	assert.Equal(t, http.StatusUnauthorized, authorized(auth, access))
+	assert.Equal(t, http.StatusUnauthorized, authorized(auth, access2))
+}
+
+func TestRefreshAfterExpiration(t *testing.T) {
+	auth := refresh_service()
+	auth.settings.ClockSkew = time.Minute
+	req, _ := http.NewRequest("POST", "/token", nil)
+
+	// Expired, but within the clock skew
+	expired := auth.new_refresh_token(auth.NewToken().Add(FamilyClaim, new_token_id()))
+	expired.Add("exp", time.Now().Add(-10*time.Second).Unix())
+	refresh, err := auth.SignedStringForHttpRequest(expired, req)
+	assert.Equal(t, nil, err)
+
+	_, _, err = auth.RefreshForHttpRequest(refresh, req)
+	assert.Equal(t, nil, err)
+	_, _, err = auth.RefreshForHttpRequest(refresh, req)
+	assert.Equal(t, ErrRefreshTokenReused, err)
+}
+
+func TestRevoke(t *testing.T) {
+	auth := refresh_service()
+	req, _ := http.NewRequest("POST", "/token", nil)
+
+	token := auth.NewToken().Add("@scopes", "read")
+	access, err := auth.SignedStringForHttpRequest(token, req)
+	assert.Equal(t, nil, err)
+	other, _ := auth.SignedStringForHttpRequest(auth.NewToken().Add("@scopes", "read"), req)
+
+	assert.Equal(t, nil, auth.Revoke(token))
+	assert.Equal(t, http.StatusUnauthorized, authorized(auth, access))
+	assert.Equal(t, http.StatusOK, authorized(auth, other))
+
+	assert.Equal(t, ErrNoRevocationStore, Init(Settings{}).Revoke(token))
+}
+
+func TestMemoryRevocationStore(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	first, _ := store.Revoke("a", time.Now().Add(time.Hour))
+	assert.Equal(t, true, first)
+	first, _ = store.Revoke("a", time.Now().Add(time.Hour))
+	assert.Equal(t, false, first)
+
+	store.Revoke("b", time.Now().Add(-time.Second))
+	revoked, _ := store.IsRevoked("b")
+	assert.Equal(t, false, revoked)
+	first, _ = store.Revoke("b", time.Time{})
+	assert.Equal(t, true, first)
+	revoked, _ = store.IsRevoked("b")
+	assert.Equal(t, true, revoked)
+}
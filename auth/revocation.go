@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Revocation of the tokens before they expire.  Every token has a jti claim, the id checked
+// against the revocation store of the settings by RequiresAuth.  The tokens issued by the
+// refresh flow also have the id of their family, revoked all at once on logout or when a
+// refresh token is reused.
+
+const (
+	JtiClaim    = "jti"
+	FamilyClaim = "@family"
+)
+
+var (
+	ErrRevokedToken      = errors.New("token-revoked")
+	ErrNoRevocationStore = errors.New("no-revocation-store")
+	ErrNoTokenId         = errors.New("no-token-id")
+)
+
+// Implemented by the services with a revocation store.
+type RevocationService interface {
+	Revoke(token *Token) error
+	RevokeFamily(token *Token) error
+	Consume(token *Token) error
+}
+
+// Stores the ids of the revoked tokens until they expire; zero expiration is never.
+type RevocationStore interface {
+	// Revokes the id, returning false if it was already revoked.
+	Revoke(id string, expires time.Time) (bool, error)
+	IsRevoked(id string) (bool, error)
+}
+
+func new_token_id() string {
+	buff := make([]byte, 16)
+	if _, err := rand.Read(buff); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buff)
+}
+
+// The id of the family in the revocation store, distinct from the token ids.
+func family_id(family string) string {
+	return "family:" + family
+}
+
+// The expiration of the token, zero if none.
+func (this *Token) Expiration() time.Time {
+	if exp, ok := this.Get("exp").(float64); ok {
+		return time.Unix(int64(exp), 0)
+	}
+	if exp, ok := this.Get("exp").(int64); ok {
+		return time.Unix(exp, 0)
+	}
+	return time.Time{}
+}
+
+// The token is accepted until it expires, within the clock skew, so it stays revoked until
+// then.  Zero if the token does not expire.
+func (this *serviceImpl) revoked_until(token *Token) time.Time {
+	exp := token.Expiration()
+	if exp.IsZero() {
+		return exp
+	}
+	return exp.Add(this.settings.ClockSkew)
+}
+
+// Revokes the token until it expires.
+func (this *serviceImpl) Revoke(token *Token) error {
+	store := this.settings.RevocationStore
+	if store == nil {
+		return ErrNoRevocationStore
+	}
+	jti := token.GetString(JtiClaim)
+	if jti == "" {
+		return ErrNoTokenId
+	}
+	_, err := store.Revoke(jti, this.revoked_until(token))
+	return err
+}
+
+// Revokes the token, failing with ErrRevokedToken if it was revoked already, so that single
+// use tokens like authorization codes are used once.
+func (this *serviceImpl) Consume(token *Token) error {
+	store := this.settings.RevocationStore
+	if store == nil {
+		return ErrNoRevocationStore
+	}
+	if err := this.check_revoked(token); err != nil {
+		return err
+	}
+	jti := token.GetString(JtiClaim)
+	if jti == "" {
+		return ErrNoTokenId
+	}
+	first, err := store.Revoke(jti, this.revoked_until(token))
+	if err != nil {
+		return err
+	}
+	if !first {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+// Revokes the family of the token: the refresh tokens and the access tokens issued with
+// them, e.g. on logout.
+func (this *serviceImpl) RevokeFamily(token *Token) error {
+	store := this.settings.RevocationStore
+	if store == nil {
+		return ErrNoRevocationStore
+	}
+	family := token.GetString(FamilyClaim)
+	if family == "" {
+		return this.Revoke(token)
+	}
+	_, err := store.Revoke(family_id(family), this.family_expiration())
+	return err
+}
+
+// The family outlives all of its tokens.
+func (this *serviceImpl) family_expiration() time.Time {
+	return this.GetTime().Add(this.refresh_ttl() + time.Hour*this.settings.TTLHours + this.settings.ClockSkew)
+}
+
+func (this *serviceImpl) check_revoked(token *Token) error {
+	store := this.settings.RevocationStore
+	if store == nil {
+		return nil
+	}
+	ids := []string{}
+	if jti := token.GetString(JtiClaim); jti != "" {
+		ids = append(ids, jti)
+	}
+	if family := token.GetString(FamilyClaim); family != "" {
+		ids = append(ids, family_id(family))
+	}
+	for _, id := range ids {
+		revoked, err := store.IsRevoked(id)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrRevokedToken
+		}
+	}
+	return nil
+}
+
+// Keeps the revoked ids in memory, for a single process or testing.
+type memory_revocation_store struct {
+	lock   sync.Mutex
+	ids    map[string]time.Time
+	purged time.Time
+}
+
+func NewMemoryRevocationStore() RevocationStore {
+	return &memory_revocation_store{ids: make(map[string]time.Time)}
+}
+
+func (this *memory_revocation_store) Revoke(id string, expires time.Time) (bool, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	now := time.Now()
+	if now.Sub(this.purged) > time.Minute {
+		for revoked, exp := range this.ids {
+			if !exp.IsZero() && now.After(exp) {
+				delete(this.ids, revoked)
+			}
+		}
+		this.purged = now
+	}
+	if exp, has := this.ids[id]; has && (exp.IsZero() || now.Before(exp)) {
+		return false, nil
+	}
+	this.ids[id] = expires
+	return true, nil
+}
+
+func (this *memory_revocation_store) IsRevoked(id string) (bool, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	exp, has := this.ids[id]
+	return has && (exp.IsZero() || time.Now().Before(exp)), nil
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"database/sql"
+	omni_sql "github.com/qorio/omni/sql"
+	"sync"
+	"time"
+)
+
+// Keeps the revoked ids in postgres.  Add RevocationSchema to the schemas of the postgres
+// connection before opening it, so the table is created and the statements prepared.
+type PostgresRevocationStore struct {
+	db     *sql.DB
+	lock   sync.Mutex
+	purged time.Time
+}
+
+const (
+	kInsertRevocation omni_sql.StatementKey = iota
+	kCountRevocation
+	kPurgeRevocations
+)
+
+var RevocationSchema = &omni_sql.Schema{
+	Platform: omni_sql.POSTGRES,
+	Name:     "auth_revocations",
+	Version:  1,
+	CreateTables: map[string]string{
+		"auth_revocations": `
+create table if not exists auth_revocations (
+    id      varchar primary key,
+    expires timestamp with time zone null
+)
+		`,
+	},
+	CreateIndexes: []string{
+		`create index auth_revocations_expires on auth_revocations (expires)`,
+	},
+	PreparedStatements: map[omni_sql.StatementKey]omni_sql.Statement{
+		// Replaces the expired revocation of the id, if any
+		kInsertRevocation: omni_sql.Statement{
+			Query: `
+insert into auth_revocations (id, expires) values ($1, $2)
+on conflict (id) do update set expires=excluded.expires
+where auth_revocations.expires is not null and auth_revocations.expires < now()
+`},
+		kCountRevocation: omni_sql.Statement{
+			Query: `
+select count(*) from auth_revocations
+where id=$1 and (expires is null or expires > now())
+`},
+		kPurgeRevocations: omni_sql.Statement{
+			Query: `
+delete from auth_revocations where expires < now()
+`},
+	},
+}
+
+func NewPostgresRevocationStore(db *sql.DB) *PostgresRevocationStore {
+	return &PostgresRevocationStore{db: db}
+}
+
+func (this *PostgresRevocationStore) Revoke(id string, expires time.Time) (bool, error) {
+	this.purge()
+	var exp interface{}
+	if !expires.IsZero() {
+		exp = expires
+	}
+	result, err := RevocationSchema.Exec(this.db, kInsertRevocation, id, exp)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+func (this *PostgresRevocationStore) IsRevoked(id string) (bool, error) {
+	row, err := RevocationSchema.QueryRow(this.db, kCountRevocation, id)
+	if err != nil {
+		return false, err
+	}
+	count := 0
+	err = row.Scan(&count)
+	return count > 0, err
+}
+
+// Deletes the expired revocations, at most once a minute.
+func (this *PostgresRevocationStore) purge() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if time.Since(this.purged) < time.Minute {
+		return
+	}
+	this.purged = time.Now()
+	if _, err := RevocationSchema.Exec(this.db, kPurgeRevocations); err != nil {
+		logger.Warning("purge-revocations-error", "error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"github.com/qorio/omni/health"
+	"time"
+)
+
+// Keeps the revoked ids in redis, shared by the instances of the service.  The keys expire
+// with the tokens.
+type RedisRevocationStore struct {
+	url    string
+	prefix string
+	pool   *redis.Pool
+}
+
+// The keys of the ids are the prefix followed by the id.
+func NewRedisRevocationStore(url, prefix string) *RedisRevocationStore {
+	store := &RedisRevocationStore{
+		url:    url,
+		prefix: prefix,
+		pool: &redis.Pool{
+			MaxIdle:     5,
+			IdleTimeout: 240 * time.Second,
+			Dial: func() (redis.Conn, error) {
+				c, err := redis.Dial("tcp", url)
+				if err != nil {
+					return nil, err
+				}
+				return c, nil
+			},
+			TestOnBorrow: func(c redis.Conn, t time.Time) error {
+				_, err := c.Do("PING")
+				return err
+			},
+		},
+	}
+	health.Register(store.health_check_name(), store.ping, health.Options{})
+	return store
+}
+
+func (this *RedisRevocationStore) health_check_name() string {
+	return "auth-revocations:" + this.url
+}
+
+func (this *RedisRevocationStore) ping() error {
+	c := this.pool.Get()
+	defer c.Close()
+	_, err := c.Do("PING")
+	return err
+}
+
+func (this *RedisRevocationStore) Revoke(id string, expires time.Time) (bool, error) {
+	c := this.pool.Get()
+	defer c.Close()
+	args := []interface{}{this.prefix + id, expires.Unix()}
+	if !expires.IsZero() {
+		// Rounded up, so the id is not forgotten before it expires
+		ttl := int64((expires.Sub(time.Now()) + time.Second - 1) / time.Second)
+		if ttl <= 0 {
+			// Expired already
+			return true, nil
+		}
+		args = append(args, "EX", ttl)
+	}
+	args = append(args, "NX")
+	_, err := redis.String(c.Do("SET", args...))
+	switch err {
+	case nil:
+		return true, nil
+	case redis.ErrNil:
+		return false, nil
+	}
+	return false, err
+}
+
+func (this *RedisRevocationStore) IsRevoked(id string) (bool, error) {
+	c := this.pool.Get()
+	defer c.Close()
+	return redis.Bool(c.Do("EXISTS", this.prefix+id))
+}
+
+func (this *RedisRevocationStore) Close() error {
+	health.Unregister(this.health_check_name())
+	return this.pool.Close()
+}
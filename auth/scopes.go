@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scopes are hierarchical, with segments separated by colons, e.g. shorty:campaigns:read.
+// A granted scope grants its descendants: shorty:campaigns grants shorty:campaigns:read.
+// A * segment matches any segment, and a trailing * any descendant but not the scope itself,
+// e.g. shorty:*:read or shorty:campaigns:*.  Flat scopes are simply scopes of one segment.
+
+const (
+	ScopeSeparator = ":"
+	ScopeWildcard  = "*"
+
+	RolesClaim = "@roles"
+)
+
+// Whether the granted scope, possibly with wildcards, grants the required scope.
+func ScopeMatches(granted, required string) bool {
+	if granted == "" || required == "" {
+		return false
+	}
+	g := strings.Split(granted, ScopeSeparator)
+	r := strings.Split(required, ScopeSeparator)
+	for i, segment := range g {
+		if segment == ScopeWildcard && i == len(g)-1 {
+			return len(r) > i
+		}
+		if i >= len(r) || (segment != ScopeWildcard && segment != r[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Requires any of the any-of scopes, if there are some, and all of the all-of scopes.
+type Requirement struct {
+	AnyOf []string
+	AllOf []string
+}
+
+func (this Requirement) String() string {
+	parts := []string{}
+	if len(this.AnyOf) > 0 {
+		parts = append(parts, "any-of("+strings.Join(this.AnyOf, ",")+")")
+	}
+	if len(this.AllOf) > 0 {
+		parts = append(parts, "all-of("+strings.Join(this.AllOf, ",")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// The scopes granted by a role, and the scopes implied by a scope, e.g. shorty:campaigns:write
+// implying shorty:campaigns:read.  The implications apply to the granted scopes that grant the
+// implying scope, and are transitive.  The roles are taken from the roles claim next to the
+// scopes of the tokens, <service>/@roles for the scopes of a service and @roles for the global
+// @scopes, so that the roles for one service grant nothing in the others.  They may also
+// appear among the scopes.  In debug mode the decisions are logged and returned
+// in the X-Auth-Decision header.
+type Policy struct {
+	Roles   map[string][]string
+	Implies map[string][]string
+	Debug   bool
+}
+
+const AuthDecisionHeader = "X-Auth-Decision"
+
+// A scope of the token or obtained from it, and how: token, role:<role> or implied-by:<scope>.
+type Grant struct {
+	Scope string
+	Via   string
+}
+
+// The decision on a requirement: the required scope that allowed or denied the request, and
+// the grant of the token that satisfied it, if any.
+type Decision struct {
+	Allowed  bool
+	Rule     string
+	Required string
+	Grant    *Grant
+}
+
+func (this Decision) String() string {
+	result := "denied"
+	if this.Allowed {
+		result = "allowed"
+	}
+	if this.Grant == nil {
+		return fmt.Sprintf("%s %s %s: not granted", result, this.Rule, this.Required)
+	}
+	return fmt.Sprintf("%s %s %s: granted by %s via %s", result, this.Rule, this.Required,
+		this.Grant.Scope, this.Grant.Via)
+}
+
+// The granted scopes with those of the roles and the implied ones.
+func (this *Policy) Expand(scopes, roles []string) []Grant {
+	grants := []Grant{}
+	seen := map[string]bool{}
+	add := func(scope, via string) {
+		if scope != "" && !seen[scope] {
+			seen[scope] = true
+			grants = append(grants, Grant{Scope: scope, Via: via})
+		}
+	}
+	for _, scope := range scopes {
+		add(scope, "token")
+	}
+	for _, role := range append(append([]string{}, roles...), scopes...) {
+		for _, scope := range this.Roles[role] {
+			add(scope, "role:"+role)
+		}
+	}
+	// Until no implication adds a scope
+	for i := 0; i < len(grants); i++ {
+		for implying, implied := range this.Implies {
+			if ScopeMatches(grants[i].Scope, implying) {
+				for _, scope := range implied {
+					add(scope, "implied-by:"+implying)
+				}
+			}
+		}
+	}
+	return grants
+}
+
+func match(grants []Grant, required string) *Grant {
+	for i, grant := range grants {
+		if ScopeMatches(grant.Scope, required) {
+			return &grants[i]
+		}
+	}
+	return nil
+}
+
+// Decides on the requirement given the scopes and the roles of the token.
+func (this *Policy) Explain(requirement Requirement, scopes, roles []string) Decision {
+	grants := this.Expand(scopes, roles)
+	decision := Decision{Allowed: true, Rule: "no-requirement"}
+	for _, required := range requirement.AllOf {
+		grant := match(grants, required)
+		decision = Decision{Allowed: grant != nil, Rule: "all-of", Required: required, Grant: grant}
+		if grant == nil {
+			return decision
+		}
+	}
+	if len(requirement.AnyOf) == 0 {
+		return decision
+	}
+	for _, required := range requirement.AnyOf {
+		if grant := match(grants, required); grant != nil {
+			return Decision{Allowed: true, Rule: "any-of", Required: required, Grant: grant}
+		}
+	}
+	return Decision{Allowed: false, Rule: "any-of", Required: strings.Join(requirement.AnyOf, "|")}
+}
+
+// Checks a single scope, e.g. as the CheckScope of the settings.
+func (this *Policy) Check(scope string, scopes []string) bool {
+	return this.Explain(Requirement{AnyOf: []string{scope}}, scopes, nil).Allowed
+}
+
+// The roles of the token, comma separated in the roles claim of the service, or in the global
+// roles claim without a service.
+func roles_from_token(token *Token, service string) []string {
+	claim := RolesClaim
+	if service != "" {
+		claim = service + "/" + RolesClaim
+	}
+	if roles := token.GetString(claim); roles != "" {
+		return strings.Split(roles, ",")
+	}
+	return nil
+}
+
+// Without a policy, the scopes are required by equality or checked with the CheckScope of
+// the settings.
+func (this *serviceImpl) decide(requirement Requirement, token *Token, scopes []string, service string) Decision {
+	if this.settings.Policy != nil {
+		return this.settings.Policy.Explain(requirement, scopes, roles_from_token(token, service))
+	}
+	check := func(required string) bool {
+		if this.CheckScope != nil {
+			return this.CheckScope(required, scopes)
+		}
+		for _, s := range scopes {
+			if s == required {
+				return true
+			}
+		}
+		return false
+	}
+	decision := Decision{Allowed: true, Rule: "no-requirement"}
+	for _, required := range requirement.AllOf {
+		if !check(required) {
+			return Decision{Allowed: false, Rule: "all-of", Required: required}
+		}
+		decision = Decision{Allowed: true, Rule: "all-of", Required: required,
+			Grant: &Grant{Scope: required, Via: "token"}}
+	}
+	if len(requirement.AnyOf) == 0 {
+		return decision
+	}
+	for _, required := range requirement.AnyOf {
+		if check(required) {
+			return Decision{Allowed: true, Rule: "any-of", Required: required,
+				Grant: &Grant{Scope: required, Via: "token"}}
+		}
+	}
+	return Decision{Allowed: false, Rule: "any-of", Required: strings.Join(requirement.AnyOf, "|")}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScopeMatches(t *testing.T) {
+	assert.Equal(t, true, ScopeMatches("read", "read"))
+	assert.Equal(t, false, ScopeMatches("read", "write"))
+	assert.Equal(t, true, ScopeMatches("shorty:campaigns", "shorty:campaigns:read"))
+	assert.Equal(t, false, ScopeMatches("shorty:campaigns:read", "shorty:campaigns"))
+	assert.Equal(t, true, ScopeMatches("shorty:campaigns:*", "shorty:campaigns:read"))
+	assert.Equal(t, true, ScopeMatches("shorty:campaigns:*", "shorty:campaigns:read:stats"))
+	assert.Equal(t, false, ScopeMatches("shorty:campaigns:*", "shorty:campaigns"))
+	assert.Equal(t, true, ScopeMatches("shorty:*:read", "shorty:links:read"))
+	assert.Equal(t, false, ScopeMatches("shorty:*:read", "shorty:links:write"))
+	assert.Equal(t, true, ScopeMatches("*", "passport"))
+	assert.Equal(t, false, ScopeMatches("", ""))
+}
+
+func TestPolicyExplain(t *testing.T) {
+	policy := &Policy{
+		Roles: map[string][]string{
+			"editor": []string{"shorty:campaigns:write"},
+		},
+		Implies: map[string][]string{
+			"shorty:campaigns:write": []string{"shorty:campaigns:read"},
+			"shorty:campaigns:read":  []string{"shorty:stats:read"},
+		},
+	}
+
+	decision := policy.Explain(Requirement{AnyOf: []string{"shorty:stats:read"}}, nil, []string{"editor"})
+	assert.Equal(t, true, decision.Allowed)
+	assert.Equal(t, "any-of", decision.Rule)
+	assert.Equal(t, Grant{Scope: "shorty:stats:read", Via: "implied-by:shorty:campaigns:read"}, *decision.Grant)
+
+	// Roles among the scopes
+	decision = policy.Explain(Requirement{AnyOf: []string{"shorty:campaigns:write"}}, []string{"editor"}, nil)
+	assert.Equal(t, Grant{Scope: "shorty:campaigns:write", Via: "role:editor"}, *decision.Grant)
+
+	requirement := Requirement{AnyOf: []string{"admin", "shorty:campaigns:read"}, AllOf: []string{"shorty:links:read"}}
+	decision = policy.Explain(requirement, []string{"shorty:campaigns:*"}, nil)
+	assert.Equal(t, false, decision.Allowed)
+	assert.Equal(t, "all-of", decision.Rule)
+	assert.Equal(t, "shorty:links:read", decision.Required)
+	assert.Equal(t, "denied all-of shorty:links:read: not granted", decision.String())
+
+	decision = policy.Explain(requirement, []string{"shorty:campaigns:*", "shorty:links"}, nil)
+	assert.Equal(t, true, decision.Allowed)
+	assert.Equal(t, "allowed any-of shorty:campaigns:read: granted by shorty:campaigns:* via token", decision.String())
+
+	decision = policy.Explain(requirement, []string{"shorty:links"}, nil)
+	assert.Equal(t, false, decision.Allowed)
+	assert.Equal(t, "admin|shorty:campaigns:read", decision.Required)
+
+	assert.Equal(t, true, policy.Check("shorty:campaigns:read", []string{"shorty"}))
+}
+
+func TestRequiresScopes(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	auth := Init(Settings{
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+		Policy: &Policy{
+			Roles: map[string][]string{"admin": []string{"*"}},
+			Debug: true,
+		},
+	})
+	requirement := Requirement{AllOf: []string{"shorty:campaigns:read", "shorty:links:read"}}
+	handler := auth.RequiresScopes("", requirement, nil, func(Context, http.ResponseWriter, *http.Request) {})
+
+	request := func(token *Token) *httptest.ResponseRecorder {
+		encoded, _ := auth.SignedStringForHttpRequest(token, nil)
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+encoded)
+		resp := httptest.NewRecorder()
+		handler(resp, req)
+		return resp
+	}
+
+	resp := request(auth.NewToken().Add("@scopes", "shorty:campaigns"))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "denied all-of shorty:links:read: not granted", resp.Header().Get(AuthDecisionHeader))
+
+	resp = request(auth.NewToken().Add("@scopes", "shorty:campaigns,shorty:links:*"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = request(auth.NewToken().Add(RolesClaim, "admin"))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "allowed all-of shorty:links:read: granted by * via role:admin", resp.Header().Get(AuthDecisionHeader))
+}
+
+func TestRequiresScopesRolesOfService(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	auth := Init(Settings{
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+		Policy: &Policy{
+			Roles: map[string][]string{"admin": []string{"*"}},
+		},
+	})
+	requirement := Requirement{AnyOf: []string{"shorty:links:read"}}
+	request := func(service string, token *Token) int {
+		get_scopes := func(token *Token) []string {
+			return strings.Split(token.GetString(service+"/@scopes"), ",")
+		}
+		handler := auth.RequiresScopes(service, requirement, get_scopes,
+			func(Context, http.ResponseWriter, *http.Request) {})
+		encoded, _ := auth.SignedStringForHttpRequest(token, nil)
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+encoded)
+		resp := httptest.NewRecorder()
+		handler(resp, req)
+		return resp.Code
+	}
+
+	token := auth.NewToken().SetAudience("svc1", "svc2").Add("svc1/"+RolesClaim, "admin")
+	assert.Equal(t, http.StatusOK, request("svc1", token))
+	assert.Equal(t, http.StatusUnauthorized, request("svc2", token))
+
+	// The global roles go with the global scopes only
+	token = auth.NewToken().SetAudience("svc1").Add(RolesClaim, "admin")
+	assert.Equal(t, http.StatusUnauthorized, request("svc1", token))
+}
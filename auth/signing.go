@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"io/ioutil"
+	"math/big"
+	"strings"
+)
+
+// The signing methods of the tokens.  The HMAC methods take the shared secret as the key,
+// the RSA and ECDSA methods PEM encoded keys: the private key to sign and the public key
+// or certificate to verify, so that the tokens can be verified without the secret.
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+
+	DefaultSigningMethod = HS256
+)
+
+var (
+	ErrUnknownSigningMethod    = errors.New("unknown-signing-method")
+	ErrUnexpectedSigningMethod = errors.New("unexpected-signing-method")
+	ErrKeyNotPEM               = errors.New("key-not-pem-encoded")
+	ErrUnsupportedKey          = errors.New("unsupported-key-type")
+	ErrBadSignature            = errors.New("bad-signature")
+)
+
+func init() {
+	for _, m := range []*signing_method_ecdsa{
+		{ES256, crypto.SHA256, 32},
+		{ES384, crypto.SHA384, 48},
+		{ES512, crypto.SHA512, 66},
+	} {
+		method := m
+		jwt.RegisterSigningMethod(method.name, func() jwt.SigningMethod { return method })
+	}
+}
+
+func signing_method(alg string) (jwt.SigningMethod, error) {
+	if alg == "" {
+		alg = DefaultSigningMethod
+	}
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return nil, ErrUnknownSigningMethod
+	}
+	return method, nil
+}
+
+func is_asymmetric(alg string) bool {
+	return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "ES")
+}
+
+// The key given to jwt-go to sign with the method: the secret as is for HMAC, the parsed
+// private key otherwise.
+func signing_key(alg string, key []byte) (interface{}, error) {
+	if !is_asymmetric(alg) {
+		return key, nil
+	}
+	return ParsePrivateKey(key)
+}
+
+func verification_key(alg string, key []byte) (interface{}, error) {
+	if !is_asymmetric(alg) {
+		return key, nil
+	}
+	return ParsePublicKey(key)
+}
+
+// Parses a PEM encoded public key: PKIX, PKCS#1 RSA, or the key of an X.509 certificate.
+// An ssh-rsa key in the authorized_keys format is accepted as well.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return parse_ssh_rsa(data)
+	}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return supported_public_key(cert.PublicKey)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return supported_public_key(key)
+}
+
+func supported_public_key(key interface{}) (crypto.PublicKey, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// The ssh-rsa key, its type, exponent and modulus in the ssh wire format (RFC 4253).
+func parse_ssh_rsa(data []byte) (crypto.PublicKey, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 || fields[0] != "ssh-rsa" {
+		return nil, ErrKeyNotPEM
+	}
+	wire, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, ErrKeyNotPEM
+	}
+	parts := [][]byte{}
+	for len(parts) < 3 {
+		if len(wire) < 4 {
+			return nil, ErrKeyNotPEM
+		}
+		n := binary.BigEndian.Uint32(wire)
+		if uint32(len(wire)-4) < n {
+			return nil, ErrKeyNotPEM
+		}
+		parts = append(parts, wire[4:4+n])
+		wire = wire[4+n:]
+	}
+	e := new(big.Int).SetBytes(parts[1])
+	if string(parts[0]) != "ssh-rsa" || !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, ErrKeyNotPEM
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(parts[2]), E: int(e.Int64())}, nil
+}
+
+// Parses a PEM encoded private key: PKCS#1 RSA, SEC 1 EC or PKCS#8.
+func ParsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrKeyNotPEM
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return key, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// Reads a PEM encoded public key or certificate, or an ssh-rsa key, returned as a PEM encoded
+// PKIX public key.
+func ReadPublicKey(filename string) (key []byte, err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Reads a PEM encoded private key, checking that it can be parsed.
+func ReadPrivateKey(filename string) (key []byte, err error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParsePrivateKey(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ECDSA signatures as in JWS (RFC 7518), the concatenated big endian r and s.
+type signing_method_ecdsa struct {
+	name     string
+	hash     crypto.Hash
+	key_size int
+}
+
+func (this *signing_method_ecdsa) Alg() string {
+	return this.name
+}
+
+func (this *signing_method_ecdsa) digest(signingString string) []byte {
+	hasher := this.hash.New()
+	hasher.Write([]byte(signingString))
+	return hasher.Sum(nil)
+}
+
+func (this *signing_method_ecdsa) Verify(signingString, signature string, key interface{}) error {
+	var public *ecdsa.PublicKey
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		public = k
+	case []byte:
+		parsed, err := ParsePublicKey(k)
+		if err != nil {
+			return err
+		}
+		if public, _ = parsed.(*ecdsa.PublicKey); public == nil {
+			return jwt.ErrInvalidKey
+		}
+	default:
+		return jwt.ErrInvalidKey
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if len(sig) != 2*this.key_size {
+		return ErrBadSignature
+	}
+	r := new(big.Int).SetBytes(sig[:this.key_size])
+	s := new(big.Int).SetBytes(sig[this.key_size:])
+	if !ecdsa.Verify(public, this.digest(signingString), r, s) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+func (this *signing_method_ecdsa) Sign(signingString string, key interface{}) (string, error) {
+	var private *ecdsa.PrivateKey
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		private = k
+	case []byte:
+		parsed, err := ParsePrivateKey(k)
+		if err != nil {
+			return "", err
+		}
+		if private, _ = parsed.(*ecdsa.PrivateKey); private == nil {
+			return "", jwt.ErrInvalidKey
+		}
+	default:
+		return "", jwt.ErrInvalidKey
+	}
+	if (private.Curve.Params().BitSize+7)/8 != this.key_size {
+		return "", jwt.ErrInvalidKey
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, private, this.digest(signingString))
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 2*this.key_size)
+	r_bytes, s_bytes := r.Bytes(), s.Bytes()
+	copy(sig[this.key_size-len(r_bytes):this.key_size], r_bytes)
+	copy(sig[2*this.key_size-len(s_bytes):], s_bytes)
+	return jwt.EncodeSegment(sig), nil
+}
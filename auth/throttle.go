@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"errors"
+	omni_http "github.com/qorio/omni/http"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLoginThrottled = errors.New("login-throttled")
+)
+
+// Throttles the logins after too many failures within the window, per account and per ip
+// address of the client.  Each attempt counts as a failure from the start, so concurrent
+// attempts cannot all get in before the first failure; a successful login takes its attempt
+// back and clears the failures of the account, not those of the ip address.  The failures
+// are kept in memory.
+type LoginThrottle struct {
+	MaxPerAccount int
+	MaxPerIp      int
+	Window        time.Duration
+	// The proxies, by address or CIDR network, whose forwarded addresses are trusted.
+	TrustedProxies []string
+	// Replaces the ip address of the client behind the trusted proxies, if not nil.
+	ClientIp func(*http.Request) string
+
+	lock     sync.Mutex
+	failures map[string][]time.Time
+	purged   time.Time
+}
+
+const (
+	DefaultMaxLoginsPerAccount = 5
+	DefaultMaxLoginsPerIp      = 20
+	DefaultLoginWindow         = 15 * time.Minute
+)
+
+// The ip address of the client is the remote address of the requests until the trusted
+// proxies are set, see http.TrustedClientIp.
+func NewLoginThrottle() *LoginThrottle {
+	return &LoginThrottle{
+		MaxPerAccount: DefaultMaxLoginsPerAccount,
+		MaxPerIp:      DefaultMaxLoginsPerIp,
+		Window:        DefaultLoginWindow,
+	}
+}
+
+func (this *LoginThrottle) keys(account string, req *http.Request) (string, string) {
+	ip := ""
+	switch {
+	case req == nil:
+	case this.ClientIp != nil:
+		ip = this.ClientIp(req)
+	default:
+		ip = omni_http.TrustedClientIp(req, this.TrustedProxies)
+	}
+	return "account:" + account, "ip:" + ip
+}
+
+// The recent failures of the key, as of now.
+func (this *LoginThrottle) recent(key string, now time.Time) []time.Time {
+	failures := this.failures[key]
+	i := 0
+	for i < len(failures) && now.Sub(failures[i]) > this.Window {
+		i++
+	}
+	if i == len(failures) {
+		delete(this.failures, key)
+		return nil
+	}
+	this.failures[key] = failures[i:]
+	return failures[i:]
+}
+
+// A login attempt, counted as a failure unless it succeeds or is cancelled.
+type LoginAttempt struct {
+	throttle    *LoginThrottle
+	account_key string
+	ip_key      string
+	at          time.Time
+}
+
+// Starts a login attempt, or returns ErrLoginThrottled and the time until the next attempt
+// is allowed if there are too many failures.
+func (this *LoginThrottle) Attempt(account string, req *http.Request) (*LoginAttempt, time.Duration, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.failures == nil {
+		this.failures = make(map[string][]time.Time)
+	}
+	now := time.Now()
+	if now.Sub(this.purged) > this.Window {
+		for key, _ := range this.failures {
+			this.recent(key, now)
+		}
+		this.purged = now
+	}
+	account_key, ip_key := this.keys(account, req)
+	wait := time.Duration(0)
+	for key, max := range map[string]int{account_key: this.MaxPerAccount, ip_key: this.MaxPerIp} {
+		failures := this.recent(key, now)
+		if max > 0 && len(failures) >= max {
+			// Until the oldest of the last max failures leaves the window
+			if w := failures[len(failures)-max].Add(this.Window).Sub(now); w > wait {
+				wait = w
+			}
+		}
+	}
+	if wait > 0 {
+		return nil, wait, ErrLoginThrottled
+	}
+	this.add(account_key, now, this.MaxPerAccount)
+	this.add(ip_key, now, this.MaxPerIp)
+	return &LoginAttempt{throttle: this, account_key: account_key, ip_key: ip_key, at: now}, 0, nil
+}
+
+// Only the last max failures matter.
+func (this *LoginThrottle) add(key string, now time.Time, max int) {
+	failures := append(this.failures[key], now)
+	if max > 0 && len(failures) > max {
+		failures = failures[len(failures)-max:]
+	}
+	this.failures[key] = failures
+}
+
+func (this *LoginThrottle) remove(key string, at time.Time) {
+	failures := this.failures[key]
+	for i, t := range failures {
+		if t.Equal(at) {
+			this.failures[key] = append(failures[:i:i], failures[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clears the failures of the account.
+func (this *LoginAttempt) Succeeded() {
+	this.throttle.lock.Lock()
+	defer this.throttle.lock.Unlock()
+	delete(this.throttle.failures, this.account_key)
+	this.throttle.remove(this.ip_key, this.at)
+}
+
+// Does not count the attempt, e.g. if the credentials could not be checked.
+func (this *LoginAttempt) Cancel() {
+	this.throttle.lock.Lock()
+	defer this.throttle.lock.Unlock()
+	this.throttle.remove(this.account_key, this.at)
+	this.throttle.remove(this.ip_key, this.at)
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/logging"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -28,6 +28,8 @@ const (
 
 var (
 	MailgunEndpointTemplate *template.Template
+
+	logger = logging.For("email")
 )
 
 func init() {
@@ -126,7 +128,7 @@ func (this *Mailgun) Send(message *Message) (*Response, error) {
 	content, err := ioutil.ReadAll(resp.Body)
 	resp.Body.Close()
 
-	glog.V(200).Infoln("Email: Response=", string(content))
+	logger.Debug("mailgun-response", "status", resp.StatusCode, "response", string(content))
 
 	r := new(Response)
 	err = json.Unmarshal(content, r)
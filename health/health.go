@@ -0,0 +1,213 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Registry of named health checks.  The liveness status (/healthz) aggregates the checks
+// registered as liveness checks, and the readiness status (/readyz) aggregates all the
+// checks.  Readiness fails as soon as the process starts shutting down so that the load
+// balancers drain the traffic before the servers stop.
+
+type Check func() error
+
+type Options struct {
+	Timeout  time.Duration // DefaultTimeout if zero
+	Liveness bool          // also a liveness check; otherwise only a readiness check
+}
+
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+var (
+	ErrTimeout = errors.New("error-health-check-timeout")
+
+	DefaultTimeout = 2 * time.Second
+)
+
+type Registry struct {
+	lock          sync.RWMutex
+	checks        map[string]registered_check
+	shutting_down bool
+}
+
+type registered_check struct {
+	check   Check
+	options Options
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Result struct {
+	Status       string        `json:"status"`
+	ShuttingDown bool          `json:"shutting_down,omitempty"`
+	Checks       []CheckResult `json:"checks"`
+}
+
+// The registry served by the runtime manager, where the omni packages register their
+// checks.
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]registered_check)}
+}
+
+// Registers the check, replacing any check of the same name.
+func (this *Registry) Register(name string, check Check, options Options) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.checks[name] = registered_check{check, options}
+}
+
+func (this *Registry) Unregister(name string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.checks, name)
+}
+
+// Marks the process as shutting down.  Readiness fails from then on.
+func (this *Registry) ShuttingDown() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.shutting_down = true
+}
+
+func (this *Registry) IsShuttingDown() bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.shutting_down
+}
+
+// Runs the liveness checks concurrently.
+func (this *Registry) Liveness() *Result {
+	return this.run(true)
+}
+
+// Runs all the checks concurrently.
+func (this *Registry) Readiness() *Result {
+	return this.run(false)
+}
+
+func (this *Registry) run(liveness bool) *Result {
+	this.lock.RLock()
+	names := []string{}
+	for name, c := range this.checks {
+		if !liveness || c.options.Liveness {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	checks := make([]registered_check, len(names))
+	for i, name := range names {
+		checks[i] = this.checks[name]
+	}
+	shutting_down := this.shutting_down
+	this.lock.RUnlock()
+
+	result := &Result{
+		Status: StatusOK,
+		Checks: make([]CheckResult, len(names)),
+	}
+	var wg sync.WaitGroup
+	for i, _ := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result.Checks[i] = run_check(names[i], checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range result.Checks {
+		if c.Status != StatusOK {
+			result.Status = StatusFailing
+		}
+	}
+	if !liveness && shutting_down {
+		result.ShuttingDown = true
+		result.Status = StatusFailing
+	}
+	return result
+}
+
+func run_check(name string, c registered_check) CheckResult {
+	timeout := c.options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.New("panic in health check")
+			}
+		}()
+		done <- c.check()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		err = ErrTimeout
+	}
+	result := CheckResult{
+		Name:      name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Serves the liveness status, with status 503 if failing.
+func (this *Registry) LivenessHandler(resp http.ResponseWriter, req *http.Request) {
+	write_result(resp, this.Liveness())
+}
+
+// Serves the readiness status, with status 503 if failing.
+func (this *Registry) ReadinessHandler(resp http.ResponseWriter, req *http.Request) {
+	write_result(resp, this.Readiness())
+}
+
+func write_result(resp http.ResponseWriter, result *Result) {
+	buff, err := json.Marshal(result)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Cache-Control", "no-cache")
+	if result.Status != StatusOK {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+	}
+	resp.Write(buff)
+}
+
+func Register(name string, check Check, options Options) {
+	Default.Register(name, check, options)
+}
+
+func Unregister(name string) {
+	Default.Unregister(name)
+}
+
+func ShuttingDown() {
+	Default.ShuttingDown()
+}
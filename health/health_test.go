@@ -0,0 +1,75 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+	r := NewRegistry()
+	r.Register("b", func() error { return nil }, Options{Liveness: true})
+	r.Register("a", func() error { return errors.New("down") }, Options{})
+
+	result := r.Readiness()
+	assert.Equal(t, StatusFailing, result.Status)
+	assert.Equal(t, 2, len(result.Checks))
+	assert.Equal(t, "a", result.Checks[0].Name)
+	assert.Equal(t, StatusFailing, result.Checks[0].Status)
+	assert.Equal(t, "down", result.Checks[0].Error)
+	assert.Equal(t, StatusOK, result.Checks[1].Status)
+
+	result = r.Liveness()
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Equal(t, 1, len(result.Checks))
+
+	r.Unregister("a")
+	assert.Equal(t, StatusOK, r.Readiness().Status)
+
+	r.ShuttingDown()
+	result = r.Readiness()
+	assert.Equal(t, StatusFailing, result.Status)
+	assert.Equal(t, true, result.ShuttingDown)
+	assert.Equal(t, StatusOK, r.Liveness().Status)
+}
+
+func TestTimeoutAndPanic(t *testing.T) {
+	r := NewRegistry()
+	r.Register("slow", func() error {
+		time.Sleep(time.Second)
+		return nil
+	}, Options{Timeout: 10 * time.Millisecond})
+	r.Register("panic", func() error { panic("boom") }, Options{})
+
+	start := time.Now()
+	result := r.Readiness()
+	assert.Equal(t, true, time.Since(start) < time.Second)
+	assert.Equal(t, StatusFailing, result.Status)
+	assert.Equal(t, StatusFailing, result.Checks[0].Status)
+	assert.Equal(t, ErrTimeout.Error(), result.Checks[1].Error)
+	assert.Equal(t, true, result.Checks[1].LatencyMs >= 10)
+}
+
+func TestHandlers(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", func() error { return errors.New("down") }, Options{})
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	resp := httptest.NewRecorder()
+	r.LivenessHandler(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/readyz", nil)
+	resp = httptest.NewRecorder()
+	r.ReadinessHandler(resp, req)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+
+	result := Result{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "db", result.Checks[0].Name)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/mssola/user_agent"
 	"github.com/nranchev/go-libGeoIP"
+	"net"
 	"net/http"
 	"strings"
 )
@@ -139,7 +140,10 @@ func (this *RequestParser) Parse(req *http.Request) (r *RequestOrigin, err error
 	return r, err
 }
 
-func (this *RequestParser) geo(req *http.Request) (string, *libgeo.Location, error) {
+// The ip address of the client, from the X-Real-Ip or X-Forwarded-For headers set by the
+// proxies, or else the remote address of the request.  The headers are set by the client
+// unless behind a proxy; see TrustedClientIp for anything security related.
+func ClientIp(req *http.Request) string {
 	ip := req.Header.Get("X-Real-Ip")
 	forwarded := req.Header.Get("X-Forwarded-For")
 	if ip == "" && forwarded == "" {
@@ -155,8 +159,57 @@ func (this *RequestParser) geo(req *http.Request) (string, *libgeo.Location, err
 		// TODO: should return first non-local address
 		ip = parts[0]
 	}
+	return strings.TrimSpace(ip)
+}
 
-	ip = strings.TrimSpace(ip)
+// The ip address of the client behind the trusted proxies, given as addresses or CIDR
+// networks.  The forwarded addresses are only taken from the trusted proxies, nearest
+// first, so the client cannot choose its address; without proxies, it is the remote
+// address of the request.
+func TrustedClientIp(req *http.Request, proxies []string) string {
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	if !trusted_proxy(ip, proxies) {
+		return ip
+	}
+	forwarded := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	if len(forwarded) == 1 && strings.TrimSpace(forwarded[0]) == "" {
+		forwarded = []string{req.Header.Get("X-Real-Ip")}
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		next := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(next) == nil {
+			break
+		}
+		ip = next
+		if !trusted_proxy(ip, proxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func trusted_proxy(ip string, proxies []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+		} else if addr.Equal(net.ParseIP(proxy)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *RequestParser) geo(req *http.Request) (string, *libgeo.Location, error) {
+	ip := ClientIp(req)
 	if ip == "" {
 		return "", nil, errors.New("Could not obtain IP address from request")
 	} else if ip == "[::1]" {
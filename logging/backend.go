@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/golang/glog"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The default backend, writing the entries as the message followed by the fields in
+// key=value form.  The entries below the warning level are written as glog infos.
+var GlogBackend Backend = glog_backend{}
+
+// The frames between the caller of the logger and the glog call.
+const glog_depth = 3
+
+type glog_backend struct{}
+
+func (this glog_backend) Write(entry *Entry) {
+	line := entry.Package + ": " + entry.Message
+	if len(entry.Fields) > 0 {
+		line += " " + format_fields(entry)
+	}
+	switch entry.Level {
+	case LevelError:
+		glog.ErrorDepth(glog_depth, line)
+	case LevelWarning:
+		glog.WarningDepth(glog_depth, line)
+	default:
+		glog.InfoDepth(glog_depth, line)
+	}
+}
+
+func format_fields(entry *Entry) string {
+	fields := make([]string, 0, len(entry.Fields))
+	for _, key := range entry.Keys() {
+		fields = append(fields, key+"="+logfmt_value(fmt.Sprint(entry.Fields[key])))
+	}
+	return strings.Join(fields, " ")
+}
+
+func logfmt_value(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// The json document of the entry, in the form of the tally events read by logstash: the
+// @timestamp, @type and @source of the entry, and the fields at the top level.
+func (this *Entry) Document() map[string]interface{} {
+	doc := make(map[string]interface{}, len(this.Fields)+5)
+	for key, value := range this.Fields {
+		switch v := value.(type) {
+		case error:
+			doc[key] = v.Error()
+		case fmt.Stringer:
+			doc[key] = v.String()
+		default:
+			doc[key] = v
+		}
+	}
+	doc["@timestamp"] = this.Time.Format(time.RFC3339Nano)
+	doc["@type"] = "log"
+	doc["@source"] = this.Package
+	doc["level"] = this.Level.String()
+	doc["message"] = this.Message
+	return doc
+}
+
+type json_backend struct {
+	writer io.Writer
+	lock   sync.Mutex
+}
+
+// A backend writing each entry as a line of json.
+func NewJSONBackend(writer io.Writer) Backend {
+	return &json_backend{writer: writer}
+}
+
+func (this *json_backend) Write(entry *Entry) {
+	doc := entry.Document()
+	buff, err := json.Marshal(doc)
+	if err != nil {
+		// Fields that cannot be encoded are written as text
+		for key, value := range entry.Fields {
+			doc[key] = fmt.Sprint(value)
+		}
+		if buff, err = json.Marshal(doc); err != nil {
+			return
+		}
+	}
+	buff = append(buff, '\n')
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.writer.Write(buff)
+}
+
+// Pushes each line written to the redis list read by the logstash redis input, see
+// etc/logstash.
+type RedisWriter struct {
+	pool *redis.Pool
+	key  string
+}
+
+// The key of the list read by the logstash pipeline.
+var DefaultRedisKey = "logstash-input"
+
+func NewRedisWriter(addr, key string) *RedisWriter {
+	if key == "" {
+		key = DefaultRedisKey
+	}
+	return &RedisWriter{
+		key: key,
+		pool: &redis.Pool{
+			MaxIdle:     2,
+			IdleTimeout: 240 * time.Second,
+			Dial: func() (redis.Conn, error) {
+				return redis.DialTimeout("tcp", addr, time.Second, time.Second, time.Second)
+			},
+		},
+	}
+}
+
+func (this *RedisWriter) Write(buff []byte) (int, error) {
+	c := this.pool.Get()
+	defer c.Close()
+	for _, line := range bytes.Split(bytes.TrimRight(buff, "\n"), []byte{'\n'}) {
+		if _, err := c.Do("RPUSH", this.key, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(buff), nil
+}
+
+func (this *RedisWriter) Close() error {
+	return this.pool.Close()
+}
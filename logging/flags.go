@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+)
+
+var (
+	ErrUnknownFormat = errors.New("logging-unknown-format")
+
+	Format    = flag.String("log_format", "glog", "The format of the logs: glog, or json for logstash")
+	LevelSpec = flag.String("log_levels", "info", "The levels of the logs, e.g. info,rest=debug,sql=warning")
+	RedisAddr = flag.String("log_redis", "", "The redis host:port where the json logs are pushed for logstash, instead of stderr")
+	RedisKey  = flag.String("log_redis_key", "logstash-input", "The redis list of the json logs")
+)
+
+// Sets the backend and the levels from the flags.  Call after parsing the flags.
+func Init() error {
+	if err := SetLevels(*LevelSpec); err != nil {
+		return err
+	}
+	switch *Format {
+	case "glog":
+		SetBackend(GlogBackend)
+	case "json":
+		if *RedisAddr != "" {
+			SetBackend(NewJSONBackend(NewRedisWriter(*RedisAddr, *RedisKey)))
+		} else {
+			SetBackend(NewJSONBackend(os.Stderr))
+		}
+	default:
+		return ErrUnknownFormat
+	}
+	return nil
+}
+
+// The levels reported and set by the manager endpoint.  Setting a package to an empty level
+// resets it to the default level.
+type LevelsConfig struct {
+	Default  string            `json:"default"`
+	Packages map[string]string `json:"packages"`
+}
+
+func CurrentLevels() LevelsConfig {
+	levels_lock.RLock()
+	defer levels_lock.RUnlock()
+	current := LevelsConfig{
+		Default:  default_level.String(),
+		Packages: make(map[string]string, len(levels)),
+	}
+	for pkg, level := range levels {
+		current.Packages[pkg] = level.String()
+	}
+	return current
+}
+
+// Applies the levels, all or none of them.
+func (this LevelsConfig) Apply() error {
+	var fallback *Level
+	if this.Default != "" {
+		level, err := ParseLevel(this.Default)
+		if err != nil {
+			return err
+		}
+		fallback = &level
+	}
+	parsed := make(map[string]*Level, len(this.Packages))
+	for pkg, name := range this.Packages {
+		if name == "" {
+			parsed[pkg] = nil
+			continue
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		parsed[pkg] = &level
+	}
+
+	if fallback != nil {
+		SetDefaultLevel(*fallback)
+	}
+	for pkg, level := range parsed {
+		if level == nil {
+			ResetLevel(pkg)
+		} else {
+			SetLevel(pkg, *level)
+		}
+	}
+	return nil
+}
+
+// Serves the levels as json, after setting them from the json body on PUT.
+func LevelsHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method == "PUT" {
+		var update LevelsConfig
+		if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := update.Apply(); err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(resp).Encode(CurrentLevels())
+}
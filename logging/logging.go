@@ -0,0 +1,276 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Leveled logging with fields.  Each package logs through its own logger, For("rest"), whose
+// verbosity is set per package at runtime.  The entries are written by the backend, glog by
+// default, or json lines for shipping to logstash.
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+// The fields of the request and trace ids.
+const (
+	RequestIdField = "request_id"
+	TraceIdField   = "trace_id"
+)
+
+// The headers of the request and trace ids, the request id header as in package api.
+var (
+	RequestIdHeader = "X-Request-Id"
+	TraceIdHeader   = "X-Trace-Id"
+)
+
+var (
+	ErrUnknownLevel = errors.New("logging-unknown-level")
+
+	level_names = map[Level]string{
+		LevelDebug:   "debug",
+		LevelInfo:    "info",
+		LevelWarning: "warning",
+		LevelError:   "error",
+	}
+)
+
+func (this Level) String() string {
+	if name, has := level_names[this]; has {
+		return name
+	}
+	return fmt.Sprintf("level-%d", int(this))
+}
+
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warn" {
+		s = "warning"
+	}
+	for level, name := range level_names {
+		if name == s {
+			return level, nil
+		}
+	}
+	return LevelInfo, ErrUnknownLevel
+}
+
+type Fields map[string]interface{}
+
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Package string
+	Message string
+	Fields  Fields
+}
+
+// The keys of the fields, sorted.
+func (this *Entry) Keys() []string {
+	keys := make([]string, 0, len(this.Fields))
+	for key, _ := range this.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type Backend interface {
+	Write(entry *Entry)
+}
+
+var (
+	backend      Backend = GlogBackend
+	backend_lock sync.RWMutex
+
+	default_level = LevelInfo
+	levels        = make(map[string]Level)
+	levels_lock   sync.RWMutex
+)
+
+// Sets the backend of all the loggers.
+func SetBackend(b Backend) {
+	backend_lock.Lock()
+	defer backend_lock.Unlock()
+	backend = b
+}
+
+func get_backend() Backend {
+	backend_lock.RLock()
+	defer backend_lock.RUnlock()
+	return backend
+}
+
+// Sets the level of the packages without a level of their own.
+func SetDefaultLevel(level Level) {
+	levels_lock.Lock()
+	defer levels_lock.Unlock()
+	default_level = level
+}
+
+// Sets the level of the package.
+func SetLevel(pkg string, level Level) {
+	levels_lock.Lock()
+	defer levels_lock.Unlock()
+	levels[pkg] = level
+}
+
+// Resets the package to the default level.
+func ResetLevel(pkg string) {
+	levels_lock.Lock()
+	defer levels_lock.Unlock()
+	delete(levels, pkg)
+}
+
+// The minimum level logged by the package.
+func GetLevel(pkg string) Level {
+	levels_lock.RLock()
+	defer levels_lock.RUnlock()
+	if level, has := levels[pkg]; has {
+		return level
+	}
+	return default_level
+}
+
+// Sets the levels from a comma-separated list of levels, e.g. "info,rest=debug,sql=warning".
+// A level without a package is the default level.
+func SetLevels(spec string) error {
+	for _, item := range strings.Split(spec, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		pkg, name := "", item
+		if i := strings.Index(item, "="); i >= 0 {
+			pkg, name = strings.TrimSpace(item[:i]), item[i+1:]
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("%s: %s", err, item)
+		}
+		if pkg == "" {
+			SetDefaultLevel(level)
+		} else {
+			SetLevel(pkg, level)
+		}
+	}
+	return nil
+}
+
+type Logger struct {
+	pkg    string
+	fields Fields
+}
+
+// The logger of the package.
+func For(pkg string) *Logger {
+	return &Logger{pkg: pkg}
+}
+
+// A logger adding the fields, given as alternating keys and values, to the entries.
+func (this *Logger) With(kv ...interface{}) *Logger {
+	return this.WithFields(to_fields(kv))
+}
+
+// A logger adding the fields to the entries.
+func (this *Logger) WithFields(fields Fields) *Logger {
+	merged := make(Fields, len(this.fields)+len(fields))
+	for k, v := range this.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{pkg: this.pkg, fields: merged}
+}
+
+func (this *Logger) WithRequestId(id string) *Logger {
+	return this.With(RequestIdField, id)
+}
+
+func (this *Logger) WithTraceId(id string) *Logger {
+	return this.With(TraceIdField, id)
+}
+
+// A logger adding the request and trace ids of the request headers, if any.
+func (this *Logger) ForRequest(req *http.Request) *Logger {
+	if req == nil {
+		return this
+	}
+	fields := Fields{}
+	if id := req.Header.Get(RequestIdHeader); id != "" {
+		fields[RequestIdField] = id
+	}
+	if id := req.Header.Get(TraceIdHeader); id != "" {
+		fields[TraceIdField] = id
+	}
+	if len(fields) == 0 {
+		return this
+	}
+	return this.WithFields(fields)
+}
+
+func (this *Logger) Package() string {
+	return this.pkg
+}
+
+// True if the entries of the level are logged, e.g. to skip building costly fields.
+func (this *Logger) Enabled(level Level) bool {
+	return level >= GetLevel(this.pkg)
+}
+
+func (this *Logger) Debug(message string, kv ...interface{}) {
+	this.log(LevelDebug, message, kv)
+}
+
+func (this *Logger) Info(message string, kv ...interface{}) {
+	this.log(LevelInfo, message, kv)
+}
+
+func (this *Logger) Warning(message string, kv ...interface{}) {
+	this.log(LevelWarning, message, kv)
+}
+
+func (this *Logger) Error(message string, kv ...interface{}) {
+	this.log(LevelError, message, kv)
+}
+
+func (this *Logger) log(level Level, message string, kv []interface{}) {
+	if !this.Enabled(level) {
+		return
+	}
+	fields := this.fields
+	if len(kv) > 0 {
+		fields = this.With(kv...).fields
+	}
+	get_backend().Write(&Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Package: this.pkg,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+// The keys are strings; a value without a key is kept under the key "value".
+func to_fields(kv []interface{}) Fields {
+	fields := make(Fields, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields["value"] = kv[i]
+			break
+		}
+		fields[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+	return fields
+}
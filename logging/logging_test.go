@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type capture struct {
+	entries []*Entry
+}
+
+func (this *capture) Write(entry *Entry) {
+	this.entries = append(this.entries, entry)
+}
+
+func reset() {
+	SetBackend(GlogBackend)
+	SetDefaultLevel(LevelInfo)
+	levels_lock.Lock()
+	levels = make(map[string]Level)
+	levels_lock.Unlock()
+}
+
+func TestLevelsAndFields(t *testing.T) {
+	defer reset()
+	c := &capture{}
+	SetBackend(c)
+
+	logger := For("test").WithRequestId("r1")
+	logger.Debug("hidden")
+	logger.Info("shown", "a", 1, "b")
+	assert.Equal(t, 1, len(c.entries))
+	assert.Equal(t, "test", c.entries[0].Package)
+	assert.Equal(t, LevelInfo, c.entries[0].Level)
+	assert.Equal(t, "r1", c.entries[0].Fields[RequestIdField])
+	assert.Equal(t, 1, c.entries[0].Fields["a"])
+	assert.Equal(t, "b", c.entries[0].Fields["value"])
+	assert.Equal(t, []string{"a", RequestIdField, "value"}, c.entries[0].Keys())
+
+	assert.Equal(t, nil, SetLevels("warning,test=debug"))
+	logger.Debug("debug")
+	For("other").Info("hidden")
+	For("other").Warning("shown")
+	assert.Equal(t, 3, len(c.entries))
+	assert.Equal(t, "debug", c.entries[1].Message)
+	assert.Equal(t, "other", c.entries[2].Package)
+
+	assert.NotEqual(t, nil, SetLevels("test=loud"))
+}
+
+func TestForRequest(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/", nil)
+	logger := For("test")
+	assert.Equal(t, logger, logger.ForRequest(req))
+
+	req.Header.Set("X-Request-Id", "r1")
+	req.Header.Set("X-Trace-Id", "t1")
+	fields := logger.ForRequest(req).fields
+	assert.Equal(t, "r1", fields[RequestIdField])
+	assert.Equal(t, "t1", fields[TraceIdField])
+}
+
+func TestJSONBackend(t *testing.T) {
+	defer reset()
+	var buff bytes.Buffer
+	SetBackend(NewJSONBackend(&buff))
+
+	For("test").Warning("failed", "error", errors.New("boom"), "count", 2, "ch", make(chan bool))
+	For("test").Info("done")
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	doc := map[string]interface{}{}
+	assert.Equal(t, nil, json.Unmarshal([]byte(lines[0]), &doc))
+	assert.Equal(t, "test", doc["@source"])
+	assert.Equal(t, "log", doc["@type"])
+	assert.Equal(t, "warning", doc["level"])
+	assert.Equal(t, "failed", doc["message"])
+	assert.Equal(t, "boom", doc["error"])
+	assert.Equal(t, "2", doc["count"])
+	assert.NotEqual(t, nil, doc["@timestamp"])
+}
+
+func TestLevelsHandler(t *testing.T) {
+	defer reset()
+	req, _ := http.NewRequest("PUT", "/logging", strings.NewReader(`{"default":"warning","packages":{"rest":"debug"}}`))
+	resp := httptest.NewRecorder()
+	LevelsHandler(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, LevelWarning, GetLevel("sql"))
+	assert.Equal(t, LevelDebug, GetLevel("rest"))
+
+	req, _ = http.NewRequest("PUT", "/logging", strings.NewReader(`{"packages":{"rest":"","sql":"bad"}}`))
+	resp = httptest.NewRecorder()
+	LevelsHandler(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, LevelDebug, GetLevel("rest"))
+
+	req, _ = http.NewRequest("PUT", "/logging", strings.NewReader(`{"packages":{"rest":""}}`))
+	resp = httptest.NewRecorder()
+	LevelsHandler(resp, req)
+	assert.Equal(t, LevelWarning, GetLevel("rest"))
+
+	req, _ = http.NewRequest("GET", "/logging", nil)
+	resp = httptest.NewRecorder()
+	LevelsHandler(resp, req)
+	current := LevelsConfig{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &current))
+	assert.Equal(t, "warning", current.Default)
+	assert.Equal(t, 0, len(current.Packages))
+}
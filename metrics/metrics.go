@@ -0,0 +1,274 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// In-process metrics registry.  Counters, gauges and histograms are grouped in families
+// by name, with one series for each combination of label values.  The registry is
+// exported in the Prometheus text format, see WriteText.
+
+type Kind string
+
+const (
+	CounterKind   Kind = "counter"
+	GaugeKind     Kind = "gauge"
+	HistogramKind Kind = "histogram"
+)
+
+var (
+	ErrBadName       = errors.New("error-bad-metric-name")
+	ErrLabelCount    = errors.New("error-label-count-mismatch")
+	ErrKindMismatch  = errors.New("error-metric-kind-mismatch")
+	ErrNegativeCount = errors.New("error-counter-decreased")
+
+	// Buckets for latencies in seconds.
+	DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	name_regex  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	label_regex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type Registry struct {
+	lock     sync.RWMutex
+	families map[string]*family
+}
+
+// The registry exported by the runtime manager and fed by the omni packages.
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+type family struct {
+	name    string
+	help    string
+	kind    Kind
+	labels  []string
+	buckets []float64
+	value   func() float64 // for gauge funcs
+
+	lock   sync.RWMutex
+	series map[string]*series
+}
+
+type series struct {
+	lock   sync.Mutex
+	labels []string
+	value  float64
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Returns the family with the name, creating it if necessary.  Registering the same name
+// again returns the existing family so that packages can declare their metrics freely,
+// but the kind and labels must agree.
+func (this *Registry) family(name, help string, kind Kind, buckets []float64, labels []string) *family {
+	if !name_regex.MatchString(name) {
+		panic(fmt.Errorf("%s: %s", ErrBadName, name))
+	}
+	for _, l := range labels {
+		if !label_regex.MatchString(l) || l == "le" {
+			panic(fmt.Errorf("%s: %s{%s}", ErrBadName, name, l))
+		}
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if f, has := this.families[name]; has {
+		if f.kind != kind || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Errorf("%s: %s", ErrKindMismatch, name))
+		}
+		return f
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	this.families[name] = f
+	return f
+}
+
+func (this *family) with(values []string) *series {
+	if len(values) != len(this.labels) {
+		panic(fmt.Errorf("%s: %s", ErrLabelCount, this.name))
+	}
+	key := strings.Join(values, "\xff")
+	this.lock.RLock()
+	s, has := this.series[key]
+	this.lock.RUnlock()
+	if has {
+		return s
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if s, has = this.series[key]; !has {
+		s = &series{labels: append([]string{}, values...)}
+		if this.kind == HistogramKind {
+			s.counts = make([]uint64, len(this.buckets))
+		}
+		this.series[key] = s
+	}
+	return s
+}
+
+type CounterVec struct{ f *family }
+type GaugeVec struct{ f *family }
+type HistogramVec struct{ f *family }
+
+type Counter struct{ s *series }
+type Gauge struct{ s *series }
+type Histogram struct {
+	f *family
+	s *series
+}
+
+func (this *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{this.family(name, help, CounterKind, nil, labels)}
+}
+
+func (this *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{this.family(name, help, GaugeKind, nil, labels)}
+}
+
+// A gauge whose value is read from the function when exported.
+func (this *Registry) NewGaugeFunc(name, help string, value func() float64) {
+	f := this.family(name, help, GaugeKind, nil, nil)
+	f.value = value
+}
+
+// A histogram with the upper bounds of the buckets, in increasing order.  The +Inf bucket
+// is implied.  DefaultBuckets is used if none are given.
+func (this *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{this.family(name, help, HistogramKind, buckets, labels)}
+}
+
+func NewCounter(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounter(name, help, labels...)
+}
+
+func NewGauge(name, help string, labels ...string) *GaugeVec {
+	return Default.NewGauge(name, help, labels...)
+}
+
+func NewGaugeFunc(name, help string, value func() float64) {
+	Default.NewGaugeFunc(name, help, value)
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// The series with the label values, in the order of the label names.
+func (this *CounterVec) With(values ...string) *Counter {
+	return &Counter{this.f.with(values)}
+}
+
+func (this *GaugeVec) With(values ...string) *Gauge {
+	return &Gauge{this.f.with(values)}
+}
+
+func (this *HistogramVec) With(values ...string) *Histogram {
+	return &Histogram{this.f, this.f.with(values)}
+}
+
+func (this *Counter) Inc() {
+	this.Add(1)
+}
+
+// Counters only go up; negative deltas panic.
+func (this *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic(ErrNegativeCount)
+	}
+	this.s.lock.Lock()
+	this.s.value += delta
+	this.s.lock.Unlock()
+}
+
+func (this *Counter) Value() float64 {
+	return this.s.get()
+}
+
+func (this *Gauge) Set(value float64) {
+	this.s.lock.Lock()
+	this.s.value = value
+	this.s.lock.Unlock()
+}
+
+func (this *Gauge) Add(delta float64) {
+	this.s.lock.Lock()
+	this.s.value += delta
+	this.s.lock.Unlock()
+}
+
+func (this *Gauge) Inc() {
+	this.Add(1)
+}
+
+func (this *Gauge) Dec() {
+	this.Add(-1)
+}
+
+func (this *Gauge) Value() float64 {
+	return this.s.get()
+}
+
+func (this *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(this.f.buckets, value)
+	this.s.lock.Lock()
+	defer this.s.lock.Unlock()
+	if i < len(this.s.counts) {
+		this.s.counts[i]++
+	}
+	this.s.count++
+	if !math.IsNaN(value) {
+		this.s.sum += value
+	}
+}
+
+// The number of observations and their sum.
+func (this *Histogram) Count() (uint64, float64) {
+	this.s.lock.Lock()
+	defer this.s.lock.Unlock()
+	return this.s.count, this.s.sum
+}
+
+func (this *series) get() float64 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.value
+}
+
+func init() {
+	start := time.Now()
+	NewGaugeFunc("process_start_time_seconds", "Start time of the process since the epoch in seconds.", func() float64 {
+		return float64(start.UnixNano()) / 1e9
+	})
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	NewGaugeFunc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", func() float64 {
+		stats := runtime.MemStats{}
+		runtime.ReadMemStats(&stats)
+		return float64(stats.HeapAlloc)
+	})
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bytes"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTextExposition(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests\nserved.", "route", "status")
+	requests.With("/a", "2xx").Inc()
+	requests.With("/a", "2xx").Add(2)
+	requests.With(`/"b"`, "5xx").Inc()
+
+	r.NewGauge("clients", "").With().Set(4)
+	r.NewGaugeFunc("answer", "The answer.", func() float64 { return 42 })
+
+	latency := r.NewHistogram("latency_seconds", "Latency.", []float64{1, 0.1}, "route")
+	latency.With("/a").Observe(0.05)
+	latency.With("/a").Observe(0.1)
+	latency.With("/a").Observe(3)
+
+	var buff bytes.Buffer
+	assert.Equal(t, nil, r.WriteText(&buff))
+	expected := []string{
+		"# HELP answer The answer.",
+		"# TYPE answer gauge",
+		"answer 42",
+		"# TYPE clients gauge",
+		"clients 4",
+		"# HELP latency_seconds Latency.",
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{route="/a",le="0.1"} 2`,
+		`latency_seconds_bucket{route="/a",le="1"} 2`,
+		`latency_seconds_bucket{route="/a",le="+Inf"} 3`,
+		`latency_seconds_sum{route="/a"} 3.15`,
+		`latency_seconds_count{route="/a"} 3`,
+		`# HELP requests_total Requests\nserved.`,
+		"# TYPE requests_total counter",
+		`requests_total{route="/\"b\"",status="5xx"} 1`,
+		`requests_total{route="/a",status="2xx"} 3`,
+		"",
+	}
+	assert.Equal(t, strings.Join(expected, "\n"), buff.String())
+
+	count, sum := latency.With("/a").Count()
+	assert.Equal(t, uint64(3), count)
+	assert.Equal(t, 3.15, sum)
+}
+
+func TestRegistration(t *testing.T) {
+	r := NewRegistry()
+	a := r.NewCounter("events_total", "", "kind")
+	b := r.NewCounter("events_total", "", "kind")
+	a.With("x").Inc()
+	assert.Equal(t, float64(1), b.With("x").Value())
+
+	panics := func(f func()) (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		f()
+		return
+	}
+	assert.Equal(t, true, panics(func() { r.NewGauge("events_total", "", "kind") }))
+	assert.Equal(t, true, panics(func() { r.NewCounter("bad-name", "") }))
+	assert.Equal(t, true, panics(func() { a.With("x", "y") }))
+	assert.Equal(t, true, panics(func() { a.With("x").Add(-1) }))
+}
+
+func TestServeHTTP(t *testing.T) {
+	NewCounter("test_served_total", "").With().Inc()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	resp := httptest.NewRecorder()
+	Default.ServeHTTP(resp, req)
+	assert.Equal(t, TextContentType, resp.Header().Get("Content-Type"))
+	assert.Equal(t, true, strings.Contains(resp.Body.String(), "\ntest_served_total 1\n"))
+	assert.Equal(t, true, strings.Contains(resp.Body.String(), "\ngo_goroutines "))
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	TextContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Writes all the metrics in the Prometheus text exposition format, sorted by name and
+// label values.
+func (this *Registry) WriteText(w io.Writer) error {
+	this.lock.RLock()
+	families := make([]*family, 0, len(this.families))
+	for _, f := range this.families {
+		families = append(families, f)
+	}
+	this.lock.RUnlock()
+	sort.Sort(by_name(families))
+
+	out := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write_text(out)
+	}
+	return out.Flush()
+}
+
+func (this *Registry) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", TextContentType)
+	this.WriteText(resp)
+}
+
+type by_name []*family
+
+func (l by_name) Len() int           { return len(l) }
+func (l by_name) Less(i, j int) bool { return l[i].name < l[j].name }
+func (l by_name) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+func (this *family) write_text(out *bufio.Writer) {
+	if this.help != "" {
+		out.WriteString("# HELP " + this.name + " " + escape_help(this.help) + "\n")
+	}
+	out.WriteString("# TYPE " + this.name + " " + string(this.kind) + "\n")
+
+	if this.value != nil {
+		out.WriteString(this.name + " " + format_float(this.value()) + "\n")
+		return
+	}
+
+	this.lock.RLock()
+	keys := make([]string, 0, len(this.series))
+	for k, _ := range this.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]*series, len(keys))
+	for i, k := range keys {
+		list[i] = this.series[k]
+	}
+	this.lock.RUnlock()
+
+	for _, s := range list {
+		s.lock.Lock()
+		switch this.kind {
+		case HistogramKind:
+			cumulative := uint64(0)
+			for i, bound := range this.buckets {
+				cumulative += s.counts[i]
+				out.WriteString(this.name + "_bucket" + this.label_text(s.labels, format_float(bound)) +
+					" " + strconv.FormatUint(cumulative, 10) + "\n")
+			}
+			out.WriteString(this.name + "_bucket" + this.label_text(s.labels, "+Inf") +
+				" " + strconv.FormatUint(s.count, 10) + "\n")
+			out.WriteString(this.name + "_sum" + this.label_text(s.labels, "") + " " + format_float(s.sum) + "\n")
+			out.WriteString(this.name + "_count" + this.label_text(s.labels, "") + " " + strconv.FormatUint(s.count, 10) + "\n")
+		default:
+			out.WriteString(this.name + this.label_text(s.labels, "") + " " + format_float(s.value) + "\n")
+		}
+		s.lock.Unlock()
+	}
+}
+
+// The labels in braces, with the le label of histogram buckets if given.
+func (this *family) label_text(values []string, le string) string {
+	pairs := []string{}
+	for i, name := range this.labels {
+		pairs = append(pairs, name+`="`+escape_label(values[i])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func format_float(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escape_help(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escape_label(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,303 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The authorize endpoint of the authorization code grant.  The errors about the client or its
+// redirect uri are rendered, the others are redirected to the client.
+func (this *Server) Authorize(resp http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		render_error(resp, req, ErrInvalidRequest.Describe(err.Error()))
+		return
+	}
+	client, err := this.settings.Clients.Client(req.Form.Get("client_id"))
+	if err != nil {
+		render_error(resp, req, client_error(err))
+		return
+	}
+	redirect_uri, err := client.redirect_uri(req.Form.Get("redirect_uri"))
+	if err != nil {
+		render_error(resp, req, err)
+		return
+	}
+	state := req.Form.Get("state")
+	fail := func(err error) {
+		e, ok := err.(*Error)
+		if !ok {
+			logger.ForRequest(req).Error("authorize-error", "error", err, "client_id", client.Id)
+			e = ErrServerError
+		}
+		redirect(resp, req, redirect_uri, url.Values{
+			"error": {e.Code}, "error_description": {e.Description}, "state": {state},
+		})
+	}
+
+	if req.Form.Get("response_type") != "code" {
+		fail(ErrUnsupportedResponseType)
+		return
+	}
+	if !client.Allows(GrantAuthorizationCode) {
+		fail(ErrUnauthorizedClient)
+		return
+	}
+	challenge, method := req.Form.Get("code_challenge"), req.Form.Get("code_challenge_method")
+	if method == "" && challenge != "" {
+		method = PKCEPlain
+	}
+	switch {
+	case method != "" && method != PKCEPlain && method != PKCES256:
+		fail(ErrInvalidRequest.Describe("unsupported code_challenge_method"))
+		return
+	case challenge == "" && client.IsPublic():
+		fail(ErrInvalidRequest.Describe("code_challenge required"))
+		return
+	}
+
+	// The user may retry the login, so no redirect
+	user, err := this.settings.Login(req)
+	if err != nil {
+		logger.ForRequest(req).Warning("login-failed", "error", err, "client_id", client.Id)
+		render_error(resp, req, ErrAccessDenied)
+		return
+	}
+	scopes, err := grant_scopes(req.Form.Get("scope"), client, user)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	code := this.settings.Auth.NewToken()
+	code.Add(auth.TokenTypeClaim, auth.TokenTypeCode).
+		Add(ClientIdClaim, client.Id).
+		Add(SubjectClaim, user.Id).
+		Add(RedirectUriClaim, req.Form.Get("redirect_uri")).
+		Add(ScopeClaim, strings.Join(scopes, " "))
+	if challenge != "" {
+		code.Add(ChallengeClaim, challenge).Add(MethodClaim, method)
+	}
+	code.SetExpiration(this.settings.CodeTTL)
+	signed, err := this.settings.Auth.SignedStringForHttpRequest(code, req)
+	if err != nil {
+		fail(err)
+		return
+	}
+	redirect(resp, req, redirect_uri, url.Values{"code": {signed}, "state": {state}})
+}
+
+// The token endpoint.  The client authenticates with basic auth or the client_id and
+// client_secret params; public clients only give their id.
+func (this *Server) Token(resp http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		render_error(resp, req, ErrInvalidRequest.Describe(err.Error()))
+		return
+	}
+	client, err := this.authenticate_client(req)
+	if err != nil {
+		render_error(resp, req, err)
+		return
+	}
+	grant := req.PostForm.Get("grant_type")
+	if !client.Allows(grant) {
+		switch grant {
+		case GrantAuthorizationCode, GrantClientCredentials, GrantRefreshToken:
+			render_error(resp, req, ErrUnauthorizedClient)
+		default:
+			render_error(resp, req, ErrUnsupportedGrantType)
+		}
+		return
+	}
+	var response *TokenResponse
+	switch grant {
+	case GrantAuthorizationCode:
+		response, err = this.exchange_code(client, req)
+	case GrantClientCredentials:
+		response, err = this.client_credentials(client, req)
+	case GrantRefreshToken:
+		response, err = this.refresh(client, req)
+	default:
+		err = ErrUnsupportedGrantType
+	}
+	if err != nil {
+		render_error(resp, req, err)
+		return
+	}
+	write_json(resp, http.StatusOK, response)
+}
+
+// The revocation endpoint of RFC 7009.  Unknown and invalid tokens are not an error.
+func (this *Server) Revoke(resp http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		render_error(resp, req, ErrInvalidRequest.Describe(err.Error()))
+		return
+	}
+	client, err := this.authenticate_client(req)
+	if err != nil {
+		render_error(resp, req, err)
+		return
+	}
+	token, err := this.settings.Auth.ParseForHttpRequest(req.PostForm.Get("token"), req)
+	if err != nil {
+		write_json(resp, http.StatusOK, struct{}{})
+		return
+	}
+	if token.GetString(ClientIdClaim) != client.Id {
+		render_error(resp, req, ErrUnauthorizedClient)
+		return
+	}
+	// The access tokens of the refresh token go with it
+	if token.IsRefreshToken() {
+		err = this.revoker.RevokeFamily(token)
+	} else {
+		err = this.revoker.Revoke(token)
+	}
+	if err != nil {
+		render_error(resp, req, err)
+		return
+	}
+	write_json(resp, http.StatusOK, struct{}{})
+}
+
+func (this *Server) authenticate_client(req *http.Request) (*Client, error) {
+	id, secret, basic := req.BasicAuth()
+	if !basic {
+		id, secret = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret")
+	}
+	if id == "" {
+		return nil, ErrInvalidClient
+	}
+	client, err := this.settings.Clients.Client(id)
+	if err != nil {
+		return nil, client_error(err)
+	}
+	if !client.CheckSecret(secret) {
+		logger.ForRequest(req).Warning("client-authentication-failed", "client_id", id)
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+func client_error(err error) error {
+	if err == ErrUnknownClient {
+		return ErrInvalidClient
+	}
+	return err
+}
+
+func (this *Server) exchange_code(client *Client, req *http.Request) (*TokenResponse, error) {
+	code, err := this.settings.Auth.ParseForHttpRequest(req.PostForm.Get("code"), req)
+	if err != nil {
+		return nil, ErrInvalidGrant.Describe(err.Error())
+	}
+	switch {
+	case code.GetString(auth.TokenTypeClaim) != auth.TokenTypeCode:
+		return nil, ErrInvalidGrant.Describe("not an authorization code")
+	case code.GetString(ClientIdClaim) != client.Id:
+		return nil, ErrInvalidGrant.Describe("code issued to another client")
+	case code.GetString(RedirectUriClaim) != req.PostForm.Get("redirect_uri"):
+		return nil, ErrInvalidGrant.Describe("redirect_uri mismatch")
+	case !verify_pkce(code.GetString(ChallengeClaim), code.GetString(MethodClaim), req.PostForm.Get("code_verifier")):
+		return nil, ErrInvalidGrant.Describe("code_verifier mismatch")
+	}
+	switch err := this.revoker.Consume(code); err {
+	case nil:
+	case auth.ErrRevokedToken:
+		logger.ForRequest(req).Warning("code-reused", "client_id", client.Id)
+		return nil, ErrInvalidGrant.Describe("code already used")
+	default:
+		return nil, err
+	}
+	token := this.access_token(client, code.GetString(SubjectClaim), split_scopes(code.GetString(ScopeClaim)))
+	access, refresh, err := this.tokens.IssueForHttpRequest(token, req)
+	if err != nil {
+		return nil, err
+	}
+	return this.response(access, refresh, code.GetString(ScopeClaim), req), nil
+}
+
+// No refresh token: the client can always authenticate again.
+func (this *Server) client_credentials(client *Client, req *http.Request) (*TokenResponse, error) {
+	if client.IsPublic() {
+		return nil, ErrUnauthorizedClient
+	}
+	scopes, err := grant_scopes(req.PostForm.Get("scope"), client, nil)
+	if err != nil {
+		return nil, err
+	}
+	access, err := this.settings.Auth.SignedStringForHttpRequest(this.access_token(client, client.Id, scopes), req)
+	if err != nil {
+		return nil, err
+	}
+	return this.response(access, "", strings.Join(scopes, " "), req), nil
+}
+
+// The new tokens have the scopes of the refresh token.
+func (this *Server) refresh(client *Client, req *http.Request) (*TokenResponse, error) {
+	refreshString := req.PostForm.Get("refresh_token")
+	token, err := this.settings.Auth.ParseForHttpRequest(refreshString, req)
+	if err != nil {
+		return nil, ErrInvalidGrant.Describe(err.Error())
+	}
+	if token.GetString(ClientIdClaim) != client.Id {
+		return nil, ErrInvalidGrant.Describe("token issued to another client")
+	}
+	access, refresh, err := this.tokens.RefreshForHttpRequest(refreshString, req)
+	switch err {
+	case nil:
+	case auth.ErrNotRefreshToken, auth.ErrRefreshTokenReused, auth.ErrRevokedToken:
+		return nil, ErrInvalidGrant.Describe(err.Error())
+	default:
+		return nil, err
+	}
+	return this.response(access, refresh, token.GetString(ScopeClaim), req), nil
+}
+
+// The access token of the subject for the client, with the scopes for the service.
+func (this *Server) access_token(client *Client, subject string, scopes []string) *auth.Token {
+	token := this.settings.Auth.NewToken()
+	token.Add(SubjectClaim, subject).
+		Add(ClientIdClaim, client.Id).
+		Add(ScopeClaim, strings.Join(scopes, " ")).
+		Add(this.settings.Service+"/@scopes", strings.Join(scopes, ","))
+	if this.settings.Service != "" {
+		token.SetAudience(this.settings.Service)
+	}
+	return token
+}
+
+func (this *Server) response(access, refresh, scope string, req *http.Request) *TokenResponse {
+	response := &TokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		RefreshToken: refresh,
+		Scope:        scope,
+	}
+	if token, err := this.settings.Auth.ParseForHttpRequest(access, req); err == nil {
+		if exp := token.Expiration(); !exp.IsZero() {
+			response.ExpiresIn = int64(exp.Sub(time.Now()) / time.Second)
+		}
+	}
+	return response
+}
+
+// Verifies the code verifier of RFC 7636 against the challenge of the code, if any.
+func verify_pkce(challenge, method, verifier string) bool {
+	if challenge == "" {
+		return true
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	if method == PKCES256 {
+		sum := sha256.Sum256([]byte(verifier))
+		verifier = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(verifier)) == 1
+}
@@ -0,0 +1,244 @@
+package oauth
+
+import (
+	"encoding/json"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"github.com/qorio/omni/logging"
+	"github.com/qorio/omni/rest"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// An OAuth2 authorization server issuing the tokens of the auth service, with the
+// authorization code grant with PKCE, the client credentials grant and the refresh token
+// grant.  The access tokens carry the granted scopes as <service>/@scopes, as expected by
+// the rest methods of the service, with the service as audience.  The authorization codes
+// are short-lived tokens of type code, used once through the revocation store of the auth
+// service.
+
+const (
+	AuthorizeEndpoint api.ServiceMethod = iota
+	TokenEndpoint
+	RevokeEndpoint
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+
+	PKCEPlain = "plain"
+	PKCES256  = "S256"
+
+	ClientIdClaim    = "client_id"
+	SubjectClaim     = auth.SubjectClaim
+	ScopeClaim       = "scope"
+	RedirectUriClaim = "redirect_uri"
+	ChallengeClaim   = "code_challenge"
+	MethodClaim      = "code_challenge_method"
+
+	DefaultCodeTTL = 10 * time.Minute
+)
+
+var (
+	logger = logging.For("oauth")
+
+	Methods = api.ServiceMethods{
+		AuthorizeEndpoint: api.MethodSpec{
+			Doc:         "Authorizes the client for the user, redirecting with an authorization code",
+			UrlRoute:    "/oauth/authorize",
+			HttpMethods: []api.HttpMethod{api.GET, api.POST},
+		},
+		TokenEndpoint: api.MethodSpec{
+			Doc:        "Issues tokens for an authorization code, client credentials or a refresh token",
+			UrlRoute:   "/oauth/token",
+			HttpMethod: api.POST,
+		},
+		RevokeEndpoint: api.MethodSpec{
+			Doc:        "Revokes an access or refresh token",
+			UrlRoute:   "/oauth/revoke",
+			HttpMethod: api.POST,
+		},
+	}
+)
+
+// The error responses of RFC 6749.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Status      int    `json:"-"`
+}
+
+func (this *Error) Error() string {
+	return this.Code
+}
+
+func (this *Error) Describe(description string) *Error {
+	return &Error{Code: this.Code, Description: description, Status: this.Status}
+}
+
+var (
+	ErrInvalidRequest          = &Error{Code: "invalid_request", Status: http.StatusBadRequest}
+	ErrInvalidClient           = &Error{Code: "invalid_client", Status: http.StatusUnauthorized}
+	ErrInvalidGrant            = &Error{Code: "invalid_grant", Status: http.StatusBadRequest}
+	ErrUnauthorizedClient      = &Error{Code: "unauthorized_client", Status: http.StatusBadRequest}
+	ErrUnsupportedGrantType    = &Error{Code: "unsupported_grant_type", Status: http.StatusBadRequest}
+	ErrUnsupportedResponseType = &Error{Code: "unsupported_response_type", Status: http.StatusBadRequest}
+	ErrInvalidScope            = &Error{Code: "invalid_scope", Status: http.StatusBadRequest}
+	ErrAccessDenied            = &Error{Code: "access_denied", Status: http.StatusUnauthorized}
+	ErrServerError             = &Error{Code: "server_error", Status: http.StatusInternalServerError}
+)
+
+// The service is the id of the service of the scopes and the audience of the tokens.  The
+// auth service must have a revocation store.  The user is identified at the authorize
+// endpoint by the login function, by default with the username and password posted, checked
+// against the user store with the passwords and the login throttle if any.
+type Settings struct {
+	Service       string
+	Auth          auth.Service
+	Clients       ClientStore
+	Users         UserStore
+	Passwords     *auth.Passwords
+	LoginThrottle *auth.LoginThrottle
+	CodeTTL       time.Duration
+	Login         func(*http.Request) (*User, error)
+}
+
+type Server struct {
+	settings Settings
+	tokens   auth.RefreshService
+	revoker  auth.RevocationService
+}
+
+// Panics if the auth service cannot issue refresh tokens or revoke tokens.
+func NewServer(settings Settings) *Server {
+	tokens, ok := settings.Auth.(auth.RefreshService)
+	if !ok {
+		panic("oauth: the auth service cannot issue refresh tokens")
+	}
+	revoker, ok := settings.Auth.(auth.RevocationService)
+	if !ok {
+		panic("oauth: the auth service cannot revoke tokens")
+	}
+	if settings.CodeTTL == 0 {
+		settings.CodeTTL = DefaultCodeTTL
+	}
+	server := &Server{settings: settings, tokens: tokens, revoker: revoker}
+	if server.settings.Login == nil {
+		server.settings.Login = server.password_login
+	}
+	return server
+}
+
+// The endpoints to bind to a rest engine.
+func (this *Server) Endpoints() []*rest.ServiceMethodImpl {
+	return []*rest.ServiceMethodImpl{
+		rest.SetHandler(Methods[AuthorizeEndpoint], this.Authorize),
+		rest.SetHandler(Methods[TokenEndpoint], this.Token),
+		rest.SetHandler(Methods[RevokeEndpoint], this.Revoke),
+	}
+}
+
+func (this *Server) Bind(engine rest.Engine) {
+	engine.Bind(this.Endpoints()...)
+}
+
+// The response of the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+func write_json(resp http.ResponseWriter, status int, v interface{}) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		status, buff = http.StatusInternalServerError, []byte(`{"error":"server_error"}`)
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Cache-Control", "no-store")
+	resp.Header().Set("Pragma", "no-cache")
+	resp.WriteHeader(status)
+	resp.Write(buff)
+}
+
+// Renders the oauth errors as such, and other errors as server errors.
+func render_error(resp http.ResponseWriter, req *http.Request, err error) {
+	e, ok := err.(*Error)
+	if !ok {
+		logger.ForRequest(req).Error("oauth-error", "error", err, "path", req.URL.Path)
+		e = ErrServerError
+	}
+	if e.Code == ErrInvalidClient.Code {
+		resp.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	write_json(resp, e.Status, e)
+}
+
+// Redirects to the redirect uri of the client with the params, e.g. the code or the error.
+func redirect(resp http.ResponseWriter, req *http.Request, uri string, params url.Values) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		render_error(resp, req, ErrInvalidRequest.Describe("bad redirect_uri"))
+		return
+	}
+	query := u.Query()
+	for key, values := range params {
+		for _, v := range values {
+			if v != "" {
+				query.Add(key, v)
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+	http.Redirect(resp, req, u.String(), http.StatusFound)
+}
+
+// The scopes are space separated in the params of the requests.
+func split_scopes(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// The requested scopes granted by the allowed scopes of the client and the user, if any.
+// All the scopes of the client the user is allowed are granted if none is requested.
+func grant_scopes(requested string, client *Client, user *User) ([]string, error) {
+	allowed := func(scope string) bool {
+		if !matches_any(client.Scopes, scope) {
+			return false
+		}
+		return user == nil || matches_any(user.Scopes, scope)
+	}
+	granted := []string{}
+	if scopes := split_scopes(requested); len(scopes) > 0 {
+		for _, scope := range scopes {
+			if !allowed(scope) {
+				return nil, ErrInvalidScope.Describe(scope)
+			}
+			granted = append(granted, scope)
+		}
+		return granted, nil
+	}
+	for _, scope := range client.Scopes {
+		if allowed(scope) {
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		return nil, ErrInvalidScope
+	}
+	return granted, nil
+}
+
+func matches_any(granted []string, scope string) bool {
+	for _, g := range granted {
+		if auth.ScopeMatches(g, scope) {
+			return true
+		}
+	}
+	return false
+}
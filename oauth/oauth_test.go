@@ -0,0 +1,194 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const (
+	test_verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFzcq0Aa"
+	test_redirect = "https://app.example.com/callback"
+)
+
+func test_server(t *testing.T) (*Server, auth.Service) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	service := auth.Init(auth.Settings{
+		TTLHours:                 1,
+		RevocationStore:          auth.NewMemoryRevocationStore(),
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+	})
+	store := NewMemoryStore()
+	store.SaveClient(&Client{
+		Id:           "app",
+		RedirectUris: []string{test_redirect},
+		Scopes:       []string{"shorty:campaigns:*"},
+	})
+	store.SaveClient(&Client{
+		Id:         "backend",
+		SecretHash: HashClientSecret("secret"),
+		Scopes:     []string{"shorty:stats:read", "shorty:links:read"},
+		Grants:     []string{GrantClientCredentials},
+	})
+	hash, err := auth.HashPassword("password")
+	assert.Equal(t, nil, err)
+	store.SaveUser(&User{Id: "u1", Username: "joe", PasswordHash: hash, Scopes: []string{"shorty:campaigns:read"}})
+
+	return NewServer(Settings{Service: "shorty", Auth: service, Clients: store, Users: store}), service
+}
+
+func post(handler http.HandlerFunc, form url.Values, basic ...string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if len(basic) == 2 {
+		req.SetBasicAuth(basic[0], basic[1])
+	}
+	resp := httptest.NewRecorder()
+	handler(resp, req)
+	return resp
+}
+
+func authorize_code(t *testing.T, server *Server, form url.Values) url.Values {
+	resp := post(server.Authorize, form)
+	assert.Equal(t, http.StatusFound, resp.Code)
+	location, err := url.Parse(resp.Header().Get("Location"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "app.example.com", location.Host)
+	return location.Query()
+}
+
+func authorize_form() url.Values {
+	sum := sha256.Sum256([]byte(test_verifier))
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"app"},
+		"state":                 {"xyz"},
+		"scope":                 {"shorty:campaigns:read"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {PKCES256},
+		"username":              {"joe"},
+		"password":              {"password"},
+	}
+}
+
+func TestAuthorizationCodeGrant(t *testing.T) {
+	server, service := test_server(t)
+
+	query := authorize_code(t, server, authorize_form())
+	assert.Equal(t, "xyz", query.Get("state"))
+	code := query.Get("code")
+	assert.NotEqual(t, "", code)
+
+	exchange := url.Values{
+		"grant_type":    {GrantAuthorizationCode},
+		"client_id":     {"app"},
+		"code":          {code},
+		"code_verifier": {"wrong-verifier-wrong-verifier-wrong-verifier"},
+	}
+	resp := post(server.Token, exchange)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Tf(t, strings.Contains(resp.Body.String(), "invalid_grant"), "body=%s", resp.Body.String())
+
+	exchange.Set("code_verifier", test_verifier)
+	resp = post(server.Token, exchange)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+	tokens := &TokenResponse{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), tokens))
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, "shorty:campaigns:read", tokens.Scope)
+	assert.NotEqual(t, "", tokens.RefreshToken)
+	assert.Tf(t, tokens.ExpiresIn > 3500, "expires_in=%d", tokens.ExpiresIn)
+
+	access, err := service.ParseForHttpRequest(tokens.AccessToken, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "u1", access.GetString("sub"))
+	assert.Equal(t, "shorty:campaigns:read", access.GetString("shorty/@scopes"))
+	assert.Equal(t, []string{"shorty"}, access.Audience())
+
+	// The code is used once
+	resp = post(server.Token, exchange)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	// The code is not an access token
+	handler := service.RequiresAuth("shorty:campaigns:read", nil, func(auth.Context, http.ResponseWriter, *http.Request) {})
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+code)
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Refresh, then revoke the new refresh token and its access tokens
+	resp = post(server.Token, url.Values{
+		"grant_type": {GrantRefreshToken}, "client_id": {"app"}, "refresh_token": {tokens.RefreshToken},
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	refreshed := &TokenResponse{}
+	json.Unmarshal(resp.Body.Bytes(), refreshed)
+	assert.Equal(t, "shorty:campaigns:read", refreshed.Scope)
+
+	resp = post(server.Revoke, url.Values{"client_id": {"app"}, "token": {refreshed.RefreshToken}})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	resp = post(server.Token, url.Values{
+		"grant_type": {GrantRefreshToken}, "client_id": {"app"}, "refresh_token": {refreshed.RefreshToken},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestAuthorizeErrors(t *testing.T) {
+	server, _ := test_server(t)
+
+	form := authorize_form()
+	form.Set("redirect_uri", "https://evil.example.com/")
+	resp := post(server.Authorize, form)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	form = authorize_form()
+	form.Del("code_challenge")
+	assert.Equal(t, "invalid_request", authorize_code(t, server, form).Get("error"))
+
+	form = authorize_form()
+	form.Set("scope", "shorty:campaigns:write")
+	query := authorize_code(t, server, form)
+	assert.Equal(t, "invalid_scope", query.Get("error"))
+	assert.Equal(t, "xyz", query.Get("state"))
+
+	form = authorize_form()
+	form.Set("password", "wrong")
+	resp = post(server.Authorize, form)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	server, service := test_server(t)
+
+	resp := post(server.Token, url.Values{"grant_type": {GrantClientCredentials}}, "backend", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.NotEqual(t, "", resp.Header().Get("WWW-Authenticate"))
+
+	resp = post(server.Token, url.Values{"grant_type": {GrantClientCredentials}}, "backend", "secret")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	tokens := &TokenResponse{}
+	json.Unmarshal(resp.Body.Bytes(), tokens)
+	assert.Equal(t, "", tokens.RefreshToken)
+	assert.Equal(t, "shorty:stats:read shorty:links:read", tokens.Scope)
+	access, _ := service.ParseForHttpRequest(tokens.AccessToken, nil)
+	assert.Equal(t, "backend", access.GetString("sub"))
+
+	// Not a grant of the client
+	resp = post(server.Token, url.Values{"grant_type": {GrantRefreshToken}}, "backend", "secret")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Tf(t, strings.Contains(resp.Body.String(), "unauthorized_client"), "body=%s", resp.Body.String())
+	// Public clients have no credentials
+	resp = post(server.Token, url.Values{"grant_type": {GrantClientCredentials}, "client_id": {"app"}})
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
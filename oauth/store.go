@@ -0,0 +1,180 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"sync"
+)
+
+var (
+	ErrUnknownClient = errors.New("unknown-client")
+	ErrUnknownUser   = errors.New("unknown-user")
+)
+
+// The secret of confidential clients is kept as its sha256, see HashClientSecret; public
+// clients have none.  The grants are all but the client credentials grant if none is given.
+type Client struct {
+	Id           string
+	SecretHash   string
+	RedirectUris []string
+	Scopes       []string
+	Grants       []string
+}
+
+// The secrets of the clients are random, so a plain hash will do.
+func HashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (this *Client) IsPublic() bool {
+	return this.SecretHash == ""
+}
+
+func (this *Client) CheckSecret(secret string) bool {
+	if this.IsPublic() {
+		return secret == ""
+	}
+	return subtle.ConstantTimeCompare([]byte(HashClientSecret(secret)), []byte(this.SecretHash)) == 1
+}
+
+func (this *Client) Allows(grant string) bool {
+	if len(this.Grants) == 0 {
+		return grant == GrantAuthorizationCode || grant == GrantRefreshToken
+	}
+	for _, g := range this.Grants {
+		if g == grant {
+			return true
+		}
+	}
+	return false
+}
+
+// The redirect uri must be one of the client's, and may be omitted if the client has one.
+func (this *Client) redirect_uri(uri string) (string, error) {
+	if uri == "" && len(this.RedirectUris) == 1 {
+		return this.RedirectUris[0], nil
+	}
+	for _, u := range this.RedirectUris {
+		if u == uri {
+			return uri, nil
+		}
+	}
+	return "", ErrInvalidRequest.Describe("unregistered redirect_uri")
+}
+
+// The password of the user is kept as hashed by auth.HashPassword.
+type User struct {
+	Id           string
+	Username     string
+	PasswordHash string
+	Scopes       []string
+}
+
+type ClientStore interface {
+	// The client, or ErrUnknownClient.
+	Client(id string) (*Client, error)
+}
+
+type UserStore interface {
+	// The user, or ErrUnknownUser.
+	User(username string) (*User, error)
+}
+
+// Implemented by the user stores saving the users, e.g. with their rehashed passwords.
+type UserSaver interface {
+	SaveUser(user *User) error
+}
+
+// The users as the credentials of the logins, with the usernames as accounts.
+type user_credentials struct {
+	store UserStore
+	user  *User
+}
+
+func (this *user_credentials) PasswordHash(username string) (string, error) {
+	user, err := this.store.User(username)
+	switch err {
+	case nil:
+		this.user = user
+		return user.PasswordHash, nil
+	case ErrUnknownUser:
+		return "", auth.ErrUnknownAccount
+	}
+	return "", err
+}
+
+func (this *user_credentials) SetPasswordHash(username, hash string) error {
+	saver, ok := this.store.(UserSaver)
+	if !ok || this.user == nil {
+		return nil
+	}
+	user := *this.user
+	user.PasswordHash = hash
+	return saver.SaveUser(&user)
+}
+
+// The user of the posted username and password, checked with the passwords and the login
+// throttle of the settings.
+func (this *Server) password_login(req *http.Request) (*User, error) {
+	if this.settings.Users == nil {
+		return nil, ErrUnknownUser
+	}
+	username, password := req.PostForm.Get("username"), req.PostForm.Get("password")
+	credentials := &user_credentials{store: this.settings.Users}
+	err := auth.Login(credentials, this.settings.Passwords, this.settings.LoginThrottle, username, password, req)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.user, nil
+}
+
+// Keeps the clients and the users in memory, for a single process or testing.
+type MemoryStore struct {
+	lock    sync.RWMutex
+	clients map[string]*Client
+	users   map[string]*User
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		clients: make(map[string]*Client),
+		users:   make(map[string]*User),
+	}
+}
+
+func (this *MemoryStore) SaveClient(client *Client) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.clients[client.Id] = client
+	return nil
+}
+
+func (this *MemoryStore) Client(id string) (*Client, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if client, has := this.clients[id]; has {
+		return client, nil
+	}
+	return nil, ErrUnknownClient
+}
+
+func (this *MemoryStore) SaveUser(user *User) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.users[user.Username] = user
+	return nil
+}
+
+func (this *MemoryStore) User(username string) (*User, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if user, has := this.users[username]; has {
+		return user, nil
+	}
+	return nil, ErrUnknownUser
+}
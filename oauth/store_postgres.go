@@ -0,0 +1,120 @@
+package oauth
+
+import (
+	"database/sql"
+	omni_sql "github.com/qorio/omni/sql"
+	"strings"
+)
+
+// Keeps the clients and the users in postgres.  Add Schema to the schemas of the postgres
+// connection before opening it, so the tables are created and the statements prepared.
+// The lists are space separated.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+const (
+	kSelectClient omni_sql.StatementKey = iota
+	kSaveClient
+	kSelectUser
+	kSaveUser
+)
+
+var Schema = &omni_sql.Schema{
+	Platform: omni_sql.POSTGRES,
+	Name:     "oauth",
+	Version:  1,
+	CreateTables: map[string]string{
+		"oauth_clients": `
+create table if not exists oauth_clients (
+    id            varchar primary key,
+    secret_hash   varchar not null default '',
+    redirect_uris text not null default '',
+    scopes        text not null default '',
+    grants        text not null default ''
+)
+		`,
+		"oauth_users": `
+create table if not exists oauth_users (
+    id            varchar primary key,
+    username      varchar not null unique,
+    password_hash varchar not null,
+    scopes        text not null default ''
+)
+		`,
+	},
+	PreparedStatements: map[omni_sql.StatementKey]omni_sql.Statement{
+		kSelectClient: omni_sql.Statement{
+			Query: `
+select id, secret_hash, redirect_uris, scopes, grants from oauth_clients where id=$1
+`},
+		kSaveClient: omni_sql.Statement{
+			Query: `
+insert into oauth_clients (id, secret_hash, redirect_uris, scopes, grants) values ($1, $2, $3, $4, $5)
+on conflict (id) do update set secret_hash=excluded.secret_hash, redirect_uris=excluded.redirect_uris,
+scopes=excluded.scopes, grants=excluded.grants
+`},
+		kSelectUser: omni_sql.Statement{
+			Query: `
+select id, username, password_hash, scopes from oauth_users where username=$1
+`},
+		kSaveUser: omni_sql.Statement{
+			Query: `
+insert into oauth_users (id, username, password_hash, scopes) values ($1, $2, $3, $4)
+on conflict (id) do update set username=excluded.username, password_hash=excluded.password_hash,
+scopes=excluded.scopes
+`},
+	},
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (this *PostgresStore) Client(id string) (*Client, error) {
+	row, err := Schema.QueryRow(this.db, kSelectClient, id)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{}
+	var uris, scopes, grants string
+	switch err := row.Scan(&client.Id, &client.SecretHash, &uris, &scopes, &grants); err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, ErrUnknownClient
+	default:
+		return nil, err
+	}
+	client.RedirectUris, client.Scopes, client.Grants = strings.Fields(uris), strings.Fields(scopes), strings.Fields(grants)
+	return client, nil
+}
+
+func (this *PostgresStore) SaveClient(client *Client) error {
+	_, err := Schema.Exec(this.db, kSaveClient, client.Id, client.SecretHash,
+		strings.Join(client.RedirectUris, " "), strings.Join(client.Scopes, " "), strings.Join(client.Grants, " "))
+	return err
+}
+
+func (this *PostgresStore) User(username string) (*User, error) {
+	row, err := Schema.QueryRow(this.db, kSelectUser, username)
+	if err != nil {
+		return nil, err
+	}
+	user := &User{}
+	var scopes string
+	switch err := row.Scan(&user.Id, &user.Username, &user.PasswordHash, &scopes); err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, ErrUnknownUser
+	default:
+		return nil, err
+	}
+	user.Scopes = strings.Fields(scopes)
+	return user, nil
+}
+
+func (this *PostgresStore) SaveUser(user *User) error {
+	_, err := Schema.Exec(this.db, kSaveUser, user.Id, user.Username, user.PasswordHash,
+		strings.Join(user.Scopes, " "))
+	return err
+}
@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gorilla/context"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/logging"
+	"net"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInternal = errors.New("error-internal")
+
+	// The claim of the auth token logged as the subject of the request.
+	AccessLogSubjectClaim = "sub"
+
+	// Receives an entry for each request served by the engine.  Set to nil to turn off
+	// the access log.
+	AccessLogger func(*AccessLogEntry) = GlogAccessLogger
+
+	access_logger = logging.For("access")
+
+	// The request ids accepted from the clients; the others are replaced.
+	request_id_regex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+)
+
+type AccessLogEntry struct {
+	RequestId     string             `json:"request_id"`
+	Time          time.Time          `json:"time"`
+	Method        string             `json:"method"`
+	Path          string             `json:"path"`
+	Route         string             `json:"route,omitempty"`
+	ServiceMethod *api.ServiceMethod `json:"service_method,omitempty"`
+	Status        int                `json:"status"`
+	Bytes         int64              `json:"bytes"`
+	Latency       time.Duration      `json:"latency"`
+	Subject       string             `json:"subject,omitempty"`
+	RemoteAddr    string             `json:"remote_addr"`
+	Panic         string             `json:"panic,omitempty"`
+}
+
+// The entry in key=value form, with the values quoted as needed.
+func (this *AccessLogEntry) String() string {
+	fields := []string{
+		"request_id=" + logfmt_value(this.RequestId),
+		"method=" + this.Method,
+		"path=" + logfmt_value(this.Path),
+		"route=" + logfmt_value(this.Route),
+	}
+	if this.ServiceMethod != nil {
+		fields = append(fields, fmt.Sprintf("service_method=%d", *this.ServiceMethod))
+	}
+	fields = append(fields,
+		fmt.Sprintf("status=%d", this.Status),
+		fmt.Sprintf("bytes=%d", this.Bytes),
+		fmt.Sprintf("latency_ms=%.3f", float64(this.Latency)/float64(time.Millisecond)),
+		"subject="+logfmt_value(this.Subject),
+		"remote_addr="+logfmt_value(this.RemoteAddr),
+	)
+	if this.Panic != "" {
+		fields = append(fields, "panic="+logfmt_value(this.Panic))
+	}
+	return strings.Join(fields, " ")
+}
+
+func logfmt_value(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// The entry as logging fields.
+func (this *AccessLogEntry) Fields() logging.Fields {
+	fields := logging.Fields{
+		logging.RequestIdField: this.RequestId,
+		"method":               this.Method,
+		"path":                 this.Path,
+		"route":                this.Route,
+		"status":               this.Status,
+		"bytes":                this.Bytes,
+		"latency_ms":           float64(this.Latency) / float64(time.Millisecond),
+		"subject":              this.Subject,
+		"remote_addr":          this.RemoteAddr,
+	}
+	if this.ServiceMethod != nil {
+		fields["service_method"] = int(*this.ServiceMethod)
+	}
+	if this.Panic != "" {
+		fields["panic"] = this.Panic
+	}
+	return fields
+}
+
+// Logs the entry through the "access" logger, in key=value form with the glog backend.
+func GlogAccessLogger(entry *AccessLogEntry) {
+	access_logger.WithFields(entry.Fields()).Info("access")
+}
+
+// The request id of the request, either from the X-Request-Id header of the client if
+// valid or assigned by the engine.
+func GetRequestId(req *http.Request) string {
+	return req.Header.Get(api.RequestIdHeader)
+}
+
+func new_request_id() string {
+	buff := make([]byte, 16)
+	if _, err := rand.Read(buff); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buff)
+}
+
+// Serves the request with a request id, recovering from panics in the handlers and
+// writing the access log entry.
+func (this *engine) serve_logged(resp http.ResponseWriter, req *http.Request, handler http.Handler) {
+	entry := &AccessLogEntry{
+		RequestId:  req.Header.Get(api.RequestIdHeader),
+		Time:       time.Now(),
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RemoteAddr: req.RemoteAddr,
+	}
+	if !request_id_regex.MatchString(entry.RequestId) {
+		entry.RequestId = new_request_id()
+		req.Header.Set(api.RequestIdHeader, entry.RequestId)
+	}
+	resp.Header().Set(api.RequestIdHeader, entry.RequestId)
+
+	recorder := &response_recorder{ResponseWriter: resp}
+	defer func() {
+		if r := recover(); r != nil {
+			entry.Panic = fmt.Sprintf("%v", r)
+			logger.WithRequestId(entry.RequestId).Error("panic", "method", req.Method, "path", req.URL.Path,
+				"panic", r, "stack", string(debug.Stack()))
+			if recorder.status == 0 {
+				RenderError(recorder, req, api.NewError(http.StatusInternalServerError, ErrInternal.Error()))
+			}
+		}
+		entry.Status = recorder.status
+		if entry.Status == 0 {
+			entry.Status = http.StatusOK
+		}
+		entry.Bytes = recorder.bytes
+		entry.Latency = time.Since(entry.Time)
+		record_request(entry)
+		if AccessLogger != nil {
+			AccessLogger(entry)
+		}
+	}()
+
+	context.Set(req, access_log_key, entry)
+	// The router does not clear the context when it redirects to the clean path
+	defer context.Clear(req)
+	handler.ServeHTTP(recorder, req)
+}
+
+func access_log_entry(req *http.Request) *AccessLogEntry {
+	if v, ok := context.GetOk(req, access_log_key); ok {
+		return v.(*AccessLogEntry)
+	}
+	return nil
+}
+
+// Records the status and size of the response.  Streaming, close notification and
+// hijacking are passed through to the underlying writer.
+type response_recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (this *response_recorder) WriteHeader(status int) {
+	if this.status == 0 {
+		this.status = status
+	}
+	this.ResponseWriter.WriteHeader(status)
+}
+
+func (this *response_recorder) Write(buff []byte) (int, error) {
+	if this.status == 0 {
+		this.status = http.StatusOK
+	}
+	n, err := this.ResponseWriter.Write(buff)
+	this.bytes += int64(n)
+	return n, err
+}
+
+func (this *response_recorder) Flush() {
+	if f, ok := this.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (this *response_recorder) CloseNotify() <-chan bool {
+	if c, ok := this.ResponseWriter.(http.CloseNotifier); ok {
+		return c.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+func (this *response_recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := this.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("hijack-not-supported")
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/bmizerany/assert"
+	"github.com/gorilla/context"
+	"github.com/qorio/omni/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func capture_access_log() (*[]*AccessLogEntry, func()) {
+	entries := []*AccessLogEntry{}
+	saved := AccessLogger
+	AccessLogger = func(entry *AccessLogEntry) {
+		entries = append(entries, entry)
+	}
+	return &entries, func() { AccessLogger = saved }
+}
+
+func TestAccessLog(t *testing.T) {
+	entries, restore := capture_access_log()
+	defer restore()
+
+	e := greeting_engine()
+	served := request_count.With("0", "/greeting/{id}", "POST", "2xx").Value()
+	req, _ := http.NewRequest("POST", "/greeting/1?repeat=2", bytes.NewBufferString(`{"name":"a"}`))
+	req.Header.Set("Accept-Language", "en")
+	req.Header.Set(api.RequestIdHeader, "abc")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "abc", resp.Header().Get(api.RequestIdHeader))
+
+	assert.Equal(t, 1, len(*entries))
+	entry := (*entries)[0]
+	assert.Equal(t, "abc", entry.RequestId)
+	assert.Equal(t, "/greeting/{id}", entry.Route)
+	assert.Equal(t, PostGreeting, *entry.ServiceMethod)
+	assert.Equal(t, http.StatusOK, entry.Status)
+	assert.Equal(t, int64(resp.Body.Len()), entry.Bytes)
+	assert.Equal(t, served+1, request_count.With("0", "/greeting/{id}", "POST", "2xx").Value())
+
+	req, _ = http.NewRequest("GET", "/greeting", nil)
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	entry = (*entries)[1]
+	assert.Equal(t, http.StatusNoContent, entry.Status)
+	assert.Equal(t, 32, len(entry.RequestId))
+	assert.Equal(t, entry.RequestId, resp.Header().Get(api.RequestIdHeader))
+
+	// Only the short ids of safe characters are taken from the clients
+	for i, id := range []string{"a b", "x\nrequest_id=forged", strings.Repeat("a", 129)} {
+		req, _ = http.NewRequest("GET", "/greeting", nil)
+		req.Header.Set(api.RequestIdHeader, id)
+		resp = httptest.NewRecorder()
+		e.ServeHTTP(resp, req)
+		entry = (*entries)[2+i]
+		assert.Equal(t, 32, len(entry.RequestId))
+		assert.Equal(t, entry.RequestId, resp.Header().Get(api.RequestIdHeader))
+		assert.Equal(t, entry.RequestId, GetRequestId(req))
+	}
+	req.Header.Set(api.RequestIdHeader, "5f0c.a-1_"+strings.Repeat("b", 119))
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, req.Header.Get(api.RequestIdHeader), (*entries)[5].RequestId)
+
+	// Redirected to the clean path without leaving the entry in the context
+	for _, path := range []string{"//greeting", "/greeting/1/../2"} {
+		req, _ = http.NewRequest("GET", path, nil)
+		resp = httptest.NewRecorder()
+		e.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusMovedPermanently, resp.Code)
+		assert.Equal(t, 0, len(context.GetAll(req)))
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	entries, restore := capture_access_log()
+	defer restore()
+
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetHandler(api.MethodSpec{
+		UrlRoute:   "/panic",
+		HttpMethod: api.GET,
+	}, func(resp http.ResponseWriter, req *http.Request) {
+		var m map[string]int
+		m["boom"] = 1
+	}))
+
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	result := api.Error{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, ErrInternal.Error(), result.Code)
+	assert.Equal(t, (*entries)[0].RequestId, result.RequestId)
+	assert.Equal(t, http.StatusInternalServerError, (*entries)[0].Status)
+	assert.NotEqual(t, "", (*entries)[0].Panic)
+
+	// the engine keeps serving
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
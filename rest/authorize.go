@@ -0,0 +1,24 @@
+package rest
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+)
+
+// Runs the authorize hook of the method before the handler, with the route variables of the
+// request.  Denials are rendered as forbidden by default.
+func (this *engine) authorized(ep *ServiceMethodImpl, handler auth.HttpHandler) auth.HttpHandler {
+	if ep.Api.Authorize == nil {
+		return handler
+	}
+	return func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		if err := ep.Api.Authorize(ctx, mux.Vars(req)); err != nil {
+			logger.ForRequest(req).Warning("not-authorized", "error", err, "path", req.URL.Path)
+			RenderError(resp, req, api.ToError(err, http.StatusForbidden))
+			return
+		}
+		handler(ctx, resp, req)
+	}
+}
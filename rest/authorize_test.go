@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorizeHook(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	service := auth.Init(auth.Settings{
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+	})
+	spec := api.ServiceMethods{
+		0: api.MethodSpec{
+			UrlRoute:   "/account/{id}",
+			HttpMethod: api.GET,
+			AuthScope:  "read",
+			Authorize:  api.ServiceClaimEqualsVar("test", "@account", "id"),
+		},
+	}
+	e := NewEngine(&spec, service, nil)
+	e.Bind(SetAuthenticatedHandler("test", spec[0], func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		resp.Write([]byte(ctx.GetStringForService("test", "@account")))
+	}))
+
+	token, _ := service.SignedStringForHttpRequest(service.NewToken().
+		Add("test/@scopes", "read").Add("test/@account", "1"), nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		e.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("/account/1")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Body.String())
+
+	resp = get("/account/2")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -0,0 +1,201 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	ContentTypeJSON      = "application/json"
+	ContentTypeProtobuf  = "application/protobuf"
+	ContentTypeProtoJSON = "application/protobuf+json"
+	ContentTypeProtoText = "text/protobuf"
+	ContentTypeMsgPack   = "application/msgpack"
+	ContentTypeCBOR      = "application/cbor"
+	ContentTypeForm      = "application/x-www-form-urlencoded"
+	ContentTypeText      = "text/plain"
+	ContentTypeHTML      = "text/html"
+)
+
+var (
+	ErrNoCodec = errors.New("error-no-codec")
+)
+
+// A codec encodes and decodes objects for a content type.  Codecs are registered by
+// content type and used for the request and response bodies, the error responses and
+// the server sent events.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	codecs_lock sync.RWMutex
+	codecs      = map[string]Codec{
+		ContentTypeJSON:      JSONCodec,
+		ContentTypeProtobuf:  ProtobufCodec,
+		ContentTypeProtoJSON: ProtoJSONCodec,
+		ContentTypeProtoText: ProtoTextCodec,
+		ContentTypeMsgPack:   MsgPackCodec,
+		ContentTypeCBOR:      CBORCodec,
+		ContentTypeForm:      FormCodec,
+		ContentTypeText:      TextCodec,
+		ContentTypeHTML:      TextCodec,
+	}
+
+	JSONCodec      Codec = json_codec(0)
+	ProtobufCodec  Codec = protobuf_codec(0)
+	TextCodec      Codec = text_codec(0)
+	ProtoTextCodec Codec = proto_text_codec(0)
+	ProtoJSONCodec Codec = proto_json_codec(0)
+	MsgPackCodec   Codec = msgpack_codec(0)
+	CBORCodec      Codec = cbor_codec(0)
+	FormCodec      Codec = form_codec(0)
+)
+
+// Registers the codec for the content type, replacing any existing one.  This should be
+// done before binding the methods that declare the content type.
+func RegisterCodec(contentType string, codec Codec) {
+	if codec == nil {
+		panic(errors.New(fmt.Sprintf("Nil codec for content type: %s", contentType)))
+	}
+	codecs_lock.Lock()
+	defer codecs_lock.Unlock()
+	codecs[media_type(contentType)] = codec
+}
+
+func GetCodec(contentType string) (Codec, bool) {
+	codecs_lock.RLock()
+	defer codecs_lock.RUnlock()
+	codec, has := codecs[media_type(contentType)]
+	return codec, has
+}
+
+// Returns the registered content types, in sorted order.
+func CodecContentTypes() []string {
+	codecs_lock.RLock()
+	defer codecs_lock.RUnlock()
+	types := []string{}
+	for ct, _ := range codecs {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Encodes the object with the codec of the content type and writes it to the response.
+// The content type header is not set if the response is part of a stream.
+func marshal(contentType string, resp http.ResponseWriter, v interface{}, noHeader ...nht) error {
+	codec, has := GetCodec(contentType)
+	if !has {
+		return ErrNoCodec
+	}
+	buff, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(noHeader) == 0 {
+		resp.Header().Set("Content-Type", contentType)
+	}
+	_, err = resp.Write(buff)
+	return err
+}
+
+func unmarshal(contentType string, body io.Reader, v interface{}) error {
+	codec, has := GetCodec(contentType)
+	if !has {
+		return ErrUnknownContentType
+	}
+	buff, err := ioutil.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(buff) == 0 {
+		return io.EOF
+	}
+	return codec.Unmarshal(buff, v)
+}
+
+type json_codec int
+
+func (json_codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (json_codec) Unmarshal(data []byte, v interface{}) error {
+	logger.Debug("unmarshal", "data", string(data))
+	return json.Unmarshal(data, v)
+}
+
+type protobuf_codec int
+
+func (protobuf_codec) Marshal(v interface{}) ([]byte, error) {
+	typed, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrIncompatibleType
+	}
+	return proto.Marshal(typed)
+}
+
+func (protobuf_codec) Unmarshal(data []byte, v interface{}) error {
+	typed, ok := v.(proto.Message)
+	if !ok {
+		return ErrIncompatibleType
+	}
+	return proto.Unmarshal(data, typed)
+}
+
+// Plain text is passed through as is.  This is also the codec of text/html, for the
+// services that render their own pages.
+type text_codec int
+
+func (text_codec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case *string:
+		return []byte(*v), nil
+	case []byte:
+		return v, nil
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	}
+	return nil, errors.New("wrong-type-expects-str-ptr")
+}
+
+func (text_codec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *string:
+		*v = string(data)
+		return nil
+	case *[]byte:
+		*v = data
+		return nil
+	}
+	return errors.New("wrong-type-expects-str-ptr")
+}
+
+type proto_text_codec int
+
+func (proto_text_codec) Marshal(v interface{}) ([]byte, error) {
+	typed, ok := v.(proto.Message)
+	if !ok {
+		return nil, ErrIncompatibleType
+	}
+	return []byte(proto.MarshalTextString(typed)), nil
+}
+
+func (proto_text_codec) Unmarshal(data []byte, v interface{}) error {
+	typed, ok := v.(proto.Message)
+	if !ok {
+		return ErrIncompatibleType
+	}
+	return proto.UnmarshalText(string(data), typed)
+}
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"errors"
+	"github.com/ugorji/go/codec"
+	"reflect"
+)
+
+// MessagePack and CBOR codecs.  The structs are encoded directly, with the names of their
+// json struct tags; byte slices are binary values, and the map keys are sorted so that the
+// encoding is deterministic.
+
+var (
+	ErrMalformedBinary = errors.New("error-malformed-binary-encoding")
+)
+
+var (
+	binary_type_infos = codec.NewTypeInfos([]string{"json"})
+	generic_map_type  = reflect.TypeOf(map[string]interface{}(nil))
+
+	msgpack_handle = &codec.MsgpackHandle{WriteExt: true}
+	cbor_handle    = &codec.CborHandle{}
+)
+
+func init() {
+	for _, h := range []*codec.BasicHandle{&msgpack_handle.BasicHandle, &cbor_handle.BasicHandle} {
+		h.TypeInfos = binary_type_infos
+		h.Canonical = true
+		h.MapType = generic_map_type
+		h.RawToString = true
+	}
+}
+
+func binary_marshal(h codec.Handle, v interface{}) ([]byte, error) {
+	var buff []byte
+	err := codec.NewEncoderBytes(&buff, h).Encode(v)
+	return buff, err
+}
+
+// The data must be a single value.
+func binary_unmarshal(h codec.Handle, data []byte, v interface{}) error {
+	dec := codec.NewDecoderBytes(data, h)
+	if err := dec.Decode(v); err != nil || dec.NumBytesRead() != len(data) {
+		return ErrMalformedBinary
+	}
+	return nil
+}
+
+type msgpack_codec int
+
+func (msgpack_codec) Marshal(v interface{}) ([]byte, error) {
+	return binary_marshal(msgpack_handle, v)
+}
+
+func (msgpack_codec) Unmarshal(data []byte, v interface{}) error {
+	return binary_unmarshal(msgpack_handle, data, v)
+}
+
+type cbor_codec int
+
+func (cbor_codec) Marshal(v interface{}) ([]byte, error) {
+	return binary_marshal(cbor_handle, v)
+}
+
+func (cbor_codec) Unmarshal(data []byte, v interface{}) error {
+	return binary_unmarshal(cbor_handle, data, v)
+}
@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Url encoded forms.  Structs are mapped by the json tag names of their fields; only
+// scalars, text marshalers and slices of them are supported.
+type form_codec int
+
+var (
+	ErrUnsupportedFormType = errors.New("error-unsupported-form-type")
+
+	text_marshaler_type   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	text_unmarshaler_type = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func (form_codec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case url.Values:
+		return []byte(v.Encode()), nil
+	case *url.Values:
+		return []byte(v.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(v).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for k, s := range v {
+			values.Set(k, s)
+		}
+		return []byte(values.Encode()), nil
+	}
+	values := url.Values{}
+	if err := struct_to_form(reflect.ValueOf(v), values); err != nil {
+		return nil, err
+	}
+	return []byte(values.Encode()), nil
+}
+
+func (form_codec) Unmarshal(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+		return nil
+	case *map[string][]string:
+		*v = values
+		return nil
+	case *map[string]string:
+		if *v == nil {
+			*v = make(map[string]string)
+		}
+		for k, _ := range values {
+			(*v)[k] = values.Get(k)
+		}
+		return nil
+	}
+	return form_to_struct(values, reflect.ValueOf(v))
+}
+
+// The form key of the struct field and whether the field is included.
+func form_key(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" || strings.HasPrefix(f.Name, "XXX_") {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+func struct_to_form(v reflect.Value, values url.Values) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ErrUnsupportedFormType
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := form_key(t.Field(i))
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < field.Len(); j++ {
+				s, ok, err := format_form_value(field.Index(j))
+				if err != nil {
+					return err
+				}
+				if ok {
+					values.Add(key, s)
+				}
+			}
+			continue
+		}
+		s, ok, err := format_form_value(field)
+		if err != nil {
+			return err
+		}
+		if ok {
+			values.Set(key, s)
+		}
+	}
+	return nil
+}
+
+func format_form_value(v reflect.Value) (string, bool, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		if v.Type().Implements(text_marshaler_type) {
+			buff, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+			return string(buff), true, err
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(text_marshaler_type) {
+		buff, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(buff), true, err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), true, nil
+		}
+	}
+	return "", false, ErrUnsupportedFormType
+}
+
+func form_to_struct(values url.Values, v reflect.Value) error {
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrUnsupportedFormType
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Struct {
+		return ErrUnsupportedFormType
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := form_key(t.Field(i))
+		if !ok {
+			continue
+		}
+		list, has := values[key]
+		if !has || len(list) == 0 {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(field.Type(), len(list), len(list))
+			for j, s := range list {
+				if err := parse_form_value(key, s, slice.Index(j)); err != nil {
+					return err
+				}
+			}
+			field.Set(slice)
+			continue
+		}
+		if err := parse_form_value(key, list[0], field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parse_form_value(key, s string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if reflect.PtrTo(v.Type()).Implements(text_unmarshaler_type) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	bad := func(err error) error {
+		return errors.New(fmt.Sprintf("Bad value for %s: %s", key, err))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return bad(err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return bad(err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return bad(err)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return bad(err)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return ErrUnsupportedFormType
+		}
+		v.SetBytes([]byte(s))
+	default:
+		return ErrUnsupportedFormType
+	}
+	return nil
+}
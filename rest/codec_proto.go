@@ -0,0 +1,255 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The canonical JSON mapping of protocol buffers, as in proto3: fields are named in lower
+// camel case, 64 bit integers are quoted, bytes are base64 encoded and enums are written
+// by name.  Unset fields are omitted.  Both the camel case and the original field names
+// are accepted when decoding.
+type proto_json_codec int
+
+var (
+	ErrUnsupportedProtoType = errors.New("error-unsupported-proto-type")
+)
+
+func (proto_json_codec) Marshal(v interface{}) ([]byte, error) {
+	if _, ok := v.(proto.Message); !ok {
+		return nil, ErrIncompatibleType
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Type().Elem().Kind() != reflect.Struct {
+		return nil, ErrIncompatibleType
+	}
+	if value.IsNil() {
+		return []byte("null"), nil
+	}
+	m, err := proto_json_message(value.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func (proto_json_codec) Unmarshal(data []byte, v interface{}) error {
+	if _, ok := v.(proto.Message); !ok {
+		return ErrIncompatibleType
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return ErrIncompatibleType
+	}
+	return proto_json_decode_message(data, value.Elem())
+}
+
+func lower_camel(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// Calls the function for each field of the message generated from the proto definition.
+func proto_fields(t reflect.Type, f func(i int, p *proto.Properties)) {
+	props := proto.GetProperties(t)
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("protobuf") == "" || i >= len(props.Prop) {
+			continue
+		}
+		f(i, props.Prop[i])
+	}
+}
+
+func proto_json_message(v reflect.Value) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	var err error
+	proto_fields(v.Type(), func(i int, p *proto.Properties) {
+		if err != nil {
+			return
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Ptr, reflect.Map:
+			if field.IsNil() {
+				return
+			}
+		case reflect.Slice:
+			if field.Len() == 0 {
+				return
+			}
+		default:
+			// proto3 scalars are unset when they have the zero value
+			if field.IsZero() {
+				return
+			}
+		}
+		var value interface{}
+		if value, err = proto_json_value(field, p); err == nil {
+			m[lower_camel(p.OrigName)] = value
+		}
+	})
+	return m, err
+}
+
+func proto_json_value(v reflect.Value, p *proto.Properties) (interface{}, error) {
+	if p.Enum != "" {
+		if s, ok := v.Interface().(fmt.Stringer); ok && v.Kind() != reflect.Slice {
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				return nil, nil
+			}
+			return s.String(), nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return proto_json_message(v.Elem())
+		}
+		return proto_json_value(v.Elem(), p)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := proto_json_value(v.Index(i), p)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	case reflect.Map:
+		m := make(map[string]interface{})
+		for _, k := range v.MapKeys() {
+			item, err := proto_json_value(v.MapIndex(k), p)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprintf("%v", k.Interface())] = item
+		}
+		return m, nil
+	case reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int32, reflect.Uint32:
+		return v.Interface(), nil
+	}
+	return nil, ErrUnsupportedProtoType
+}
+
+func proto_json_decode_message(data []byte, v reflect.Value) error {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var err error
+	proto_fields(v.Type(), func(i int, p *proto.Properties) {
+		if err != nil {
+			return
+		}
+		data, has := raw[lower_camel(p.OrigName)]
+		if !has {
+			data, has = raw[p.OrigName]
+		}
+		if !has || string(data) == "null" {
+			return
+		}
+		err = proto_json_decode(data, v.Field(i))
+	})
+	return err
+}
+
+func proto_json_decode(data []byte, v reflect.Value) error {
+	t := v.Type()
+	// enums and other types that decode themselves
+	if reflect.PtrTo(t).Implements(json_unmarshaler_type) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		if t.Elem().Kind() == reflect.Struct {
+			return proto_json_decode_message(data, v.Elem())
+		}
+		return proto_json_decode(data, v.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return json.Unmarshal(data, v.Addr().Interface())
+		}
+		list := []json.RawMessage{}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(t, len(list), len(list))
+		for i, item := range list {
+			if err := proto_json_decode(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Map:
+		m := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		result := reflect.MakeMap(t)
+		for k, item := range m {
+			key := reflect.New(t.Key()).Elem()
+			if err := proto_json_decode(json_quoted(k, t.Key().Kind()), key); err != nil {
+				return err
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := proto_json_decode(item, value); err != nil {
+				return err
+			}
+			result.SetMapIndex(key, value)
+		}
+		v.Set(result)
+		return nil
+	case reflect.Int32, reflect.Int64, reflect.Uint32, reflect.Uint64:
+		// integers may be quoted
+		if len(data) > 0 && data[0] == '"' {
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return err
+			}
+			data = []byte(s)
+		}
+		return json.Unmarshal(data, v.Addr().Interface())
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	return ErrUnsupportedProtoType
+}
+
+var json_unmarshaler_type = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Map keys are always strings in json; this returns the key as the json of its kind.
+func json_quoted(key string, kind reflect.Kind) []byte {
+	if kind == reflect.String {
+		buff, _ := json.Marshal(key)
+		return buff
+	}
+	return bytes.TrimSpace([]byte(key))
+}
@@ -0,0 +1,225 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/bmizerany/assert"
+	"github.com/golang/protobuf/proto"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"github.com/qorio/omni/tally"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func test_event() *tally.Event {
+	return &tally.Event{
+		AppKey:    proto.String("app"),
+		Timestamp: proto.Float64(1425.5),
+		Type:      proto.String("install"),
+		Source:    proto.String("ios"),
+		Location:  &tally.Location{Lon: proto.Float64(-122.4), Lat: proto.Float64(37.8)},
+		Attributes: []*tally.Attribute{
+			{Key: proto.String("build"), IntValue: proto.Int64(1 << 40)},
+			{Key: proto.String("icon"), ContentValue: &tally.Content{Mime: proto.String("image/png"), Data: []byte{0, 1, 2}}},
+		},
+	}
+}
+
+func TestBinaryCodecsRoundTrip(t *testing.T) {
+	for _, codec := range []Codec{MsgPackCodec, CBORCodec} {
+		buff, err := codec.Marshal(test_event())
+		assert.Equal(t, nil, err)
+
+		event := &tally.Event{}
+		assert.Equal(t, nil, codec.Unmarshal(buff, event))
+		assert.Equal(t, true, proto.Equal(test_event(), event))
+
+		var generic map[string]interface{}
+		assert.Equal(t, nil, codec.Unmarshal(buff, &generic))
+		assert.Equal(t, "install", generic["type"])
+	}
+}
+
+func TestMsgPackEncoding(t *testing.T) {
+	buff, err := MsgPackCodec.Marshal(map[string]interface{}{"a": 1, "b": []int{-1, 300}, "c": nil})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0x92, 0xff, 0xd1, 0x01, 0x2c, 0xa1, 'c', 0xc0}, buff)
+
+	// binary values from clients decode into byte slices
+	content := &tally.Content{}
+	err = MsgPackCodec.Unmarshal([]byte{0x82, 0xa4, 'm', 'i', 'm', 'e', 0xa1, 'x', 0xa4, 'd', 'a', 't', 'a', 0xc4, 0x02, 7, 8}, content)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte{7, 8}, content.Data)
+
+	assert.Equal(t, ErrMalformedBinary, MsgPackCodec.Unmarshal([]byte{0xdc, 0xff, 0xff}, content))
+
+	// byte slices are binary values
+	buff, err = MsgPackCodec.Marshal(&tally.Content{Data: []byte{7, 8}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte{0x81, 0xa4, 'd', 'a', 't', 'a', 0xc4, 0x02, 7, 8}, buff)
+}
+
+func TestCBOREncoding(t *testing.T) {
+	buff, err := CBORCodec.Marshal(map[string]interface{}{"a": 1, "b": []int{-1, 500}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte{0xa2, 0x61, 'a', 0x01, 0x61, 'b', 0x82, 0x20, 0x19, 0x01, 0xf4}, buff)
+
+	// indefinite length map with a half precision float
+	var v map[string]interface{}
+	err = CBORCodec.Unmarshal([]byte{0xbf, 0x61, 'x', 0xf9, 0x3e, 0x00, 0xff}, &v)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1.5, v["x"])
+
+	assert.Equal(t, ErrMalformedBinary, CBORCodec.Unmarshal([]byte{0x82, 0x01}, &v))
+
+	buff, err = CBORCodec.Marshal(&tally.Content{Data: []byte{7, 8}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte{0xa1, 0x64, 'd', 'a', 't', 'a', 0x42, 7, 8}, buff)
+}
+
+func TestFormCodec(t *testing.T) {
+	type form struct {
+		Name   string   `json:"name"`
+		Count  int      `json:"count"`
+		Tags   []string `json:"tags"`
+		Active *bool    `json:"active"`
+		Secret string   `json:"-"`
+	}
+	active := true
+	buff, err := FormCodec.Marshal(&form{Name: "a b", Count: 3, Tags: []string{"x", "y"}, Active: &active, Secret: "s"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "active=true&count=3&name=a+b&tags=x&tags=y", string(buff))
+
+	decoded := form{}
+	assert.Equal(t, nil, FormCodec.Unmarshal(buff, &decoded))
+	assert.Equal(t, "a b", decoded.Name)
+	assert.Equal(t, 3, decoded.Count)
+	assert.Equal(t, []string{"x", "y"}, decoded.Tags)
+	assert.Equal(t, true, *decoded.Active)
+
+	assert.NotEqual(t, nil, FormCodec.Unmarshal([]byte("count=x"), &decoded))
+
+	values := url.Values{}
+	assert.Equal(t, nil, FormCodec.Unmarshal(buff, &values))
+	assert.Equal(t, "3", values.Get("count"))
+}
+
+func TestProtoCodecs(t *testing.T) {
+	buff, err := ProtoJSONCodec.Marshal(test_event())
+	assert.Equal(t, nil, err)
+
+	generic := map[string]interface{}{}
+	assert.Equal(t, nil, json.Unmarshal(buff, &generic))
+	assert.Equal(t, "app", generic["appKey"])
+	attr := generic["attributes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "1099511627776", attr["intValue"])
+	_, has := attr["stringValue"]
+	assert.Equal(t, false, has)
+
+	event := &tally.Event{}
+	assert.Equal(t, nil, ProtoJSONCodec.Unmarshal(buff, event))
+	assert.Equal(t, true, proto.Equal(test_event(), event))
+
+	// original field names are accepted as well
+	attribute := &tally.Attribute{}
+	assert.Equal(t, nil, ProtoJSONCodec.Unmarshal([]byte(`{"key":"k","int_value":12}`), attribute))
+	assert.Equal(t, int64(12), attribute.GetIntValue())
+
+	buff, err = ProtoTextCodec.Marshal(test_event())
+	assert.Equal(t, nil, err)
+	event = &tally.Event{}
+	assert.Equal(t, nil, ProtoTextCodec.Unmarshal(buff, event))
+	assert.Equal(t, true, proto.Equal(test_event(), event))
+
+	_, err = ProtoJSONCodec.Marshal(&greeting{})
+	assert.Equal(t, ErrIncompatibleType, err)
+}
+
+type upper_codec int
+
+func (upper_codec) Marshal(v interface{}) ([]byte, error) {
+	return bytes.ToUpper([]byte(v.(*greeting).Name)), nil
+}
+
+func (upper_codec) Unmarshal(data []byte, v interface{}) error {
+	v.(*greeting).Name = string(bytes.ToLower(data))
+	return nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("text/x-upper; charset=utf-8", upper_codec(0))
+	defer func() {
+		codecs_lock.Lock()
+		delete(codecs, "text/x-upper")
+		codecs_lock.Unlock()
+	}()
+
+	_, has := GetCodec("text/x-upper")
+	assert.Equal(t, true, has)
+
+	spec := api.MethodSpec{
+		UrlRoute:     "/echo",
+		HttpMethod:   api.POST,
+		ContentTypes: []string{"text/x-upper", ContentTypeMsgPack},
+		RequestBody: func(req *http.Request) interface{} {
+			return &greeting{}
+		},
+	}
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetTypedHandler("test", spec, func(ctx auth.Context, req *Request) (interface{}, error) {
+		return req.Body, nil
+	}))
+
+	req, _ := http.NewRequest("POST", "/echo", bytes.NewBufferString("HELLO"))
+	req.Header.Set("Content-Type", "text/x-upper")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/x-upper", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "HELLO", resp.Body.String())
+
+	req, _ = http.NewRequest("POST", "/echo", bytes.NewBufferString("HELLO"))
+	req.Header.Set("Content-Type", "text/x-upper")
+	req.Header.Set("Accept", ContentTypeMsgPack)
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	result := greeting{}
+	assert.Equal(t, nil, MsgPackCodec.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "hello", result.Name)
+}
+
+func TestBindUnknownContentType(t *testing.T) {
+	defer func() {
+		assert.NotEqual(t, nil, recover())
+	}()
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetHandler(api.MethodSpec{
+		UrlRoute:     "/png",
+		HttpMethod:   api.GET,
+		ContentTypes: []string{"image/png"},
+	}, func(resp http.ResponseWriter, req *http.Request) {}))
+	t.Fatal("should have panicked")
+}
+
+func TestBindHTML(t *testing.T) {
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetHandler(api.MethodSpec{
+		UrlRoute:     "/html",
+		HttpMethod:   api.GET,
+		ContentTypes: []string{ContentTypeHTML},
+	}, func(resp http.ResponseWriter, req *http.Request) {
+		marshal(content_type_for_response(req), resp, "<html><body>hello</body></html>")
+	}))
+
+	req, _ := http.NewRequest("GET", "/html", nil)
+	req.Header.Set("Accept", "text/html,*/*;q=0.8")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeHTML, resp.Header().Get("Content-Type"))
+	assert.Equal(t, "<html><body>hello</body></html>", resp.Body.String())
+}
@@ -0,0 +1,194 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/qorio/omni/api"
+	"html"
+	"net/http"
+)
+
+// Renders the error with the code and status.  This can be used as the ErrorRenderer of
+// the auth settings so that authorization failures are rendered like all other errors.
+var ErrorRenderer = func(resp http.ResponseWriter, req *http.Request, code string, status int) error {
+	return RenderError(resp, req, api.NewError(status, code))
+}
+
+// Renders the error in the content type negotiated from the Accept header.  Any of the
+// response content types of the method, or html, can be rendered.  JSON is used if none
+// is acceptable.  The request id is set from the X-Request-Id header if not already set.
+func RenderError(resp http.ResponseWriter, req *http.Request, e *api.Error) error {
+	rendered := *e
+	if rendered.RequestId == "" {
+		rendered.RequestId = req.Header.Get(api.RequestIdHeader)
+	}
+
+	offers := append(response_offers(GetMethodSpec(req)), ContentTypeHTML)
+	contentType, err := negotiate_offers(req, offers)
+	if err != nil {
+		contentType = ContentTypeJSON
+	}
+
+	var buff []byte
+	if contentType == ContentTypeHTML {
+		buff = []byte(error_html(&rendered))
+	} else {
+		buff, err = encode_error(contentType, &rendered)
+		if err != nil {
+			contentType = ContentTypeJSON
+			if buff, err = JSONCodec.Marshal(&rendered); err != nil {
+				return err
+			}
+		}
+	}
+	resp.Header().Set("Content-Type", contentType)
+	resp.WriteHeader(rendered.Status)
+	_, err = resp.Write(buff)
+	return err
+}
+
+// Encodes the error with the codec.  Codecs for protocol buffers are given the equivalent
+// ErrorMessage.
+func encode_error(contentType string, e *api.Error) ([]byte, error) {
+	codec, has := GetCodec(contentType)
+	if !has {
+		return nil, ErrNoCodec
+	}
+	buff, err := codec.Marshal(e)
+	if err == ErrIncompatibleType {
+		return codec.Marshal(error_message(e))
+	}
+	return buff, err
+}
+
+func error_html(e *api.Error) string {
+	page := fmt.Sprintf("<html><body><h1>%d %s</h1><p>%s</p>", e.Status,
+		html.EscapeString(e.Code), html.EscapeString(e.Message))
+	if len(e.Fields) > 0 {
+		page += "<ul>"
+		for _, f := range e.Fields {
+			page += fmt.Sprintf("<li>%s: %s</li>", html.EscapeString(f.Field), html.EscapeString(f.Message))
+		}
+		page += "</ul>"
+	}
+	if e.RequestId != "" {
+		page += fmt.Sprintf("<p>Request id: %s</p>", html.EscapeString(e.RequestId))
+	}
+	return page + "</body></html>"
+}
+
+// The protocol buffer form of api.Error, as
+//
+//	message ErrorMessage {
+//	  optional int32 status = 1;
+//	  optional string code = 2;
+//	  optional string message = 3;
+//	  repeated FieldErrorMessage fields = 4;
+//	  optional string request_id = 5;
+//	}
+//
+//	message FieldErrorMessage {
+//	  optional string field = 1;
+//	  optional string rule = 2;
+//	  optional string message = 3;
+//	}
+type ErrorMessage struct {
+	Status           *int32               `protobuf:"varint,1,opt,name=status" json:"status,omitempty"`
+	Code             *string              `protobuf:"bytes,2,opt,name=code" json:"code,omitempty"`
+	Message          *string              `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+	Fields           []*FieldErrorMessage `protobuf:"bytes,4,rep,name=fields" json:"fields,omitempty"`
+	RequestId        *string              `protobuf:"bytes,5,opt,name=request_id" json:"request_id,omitempty"`
+	XXX_unrecognized []byte               `json:"-"`
+}
+
+func (m *ErrorMessage) Reset()         { *m = ErrorMessage{} }
+func (m *ErrorMessage) String() string { return proto.CompactTextString(m) }
+func (*ErrorMessage) ProtoMessage()    {}
+
+func (m *ErrorMessage) GetStatus() int32 {
+	if m != nil && m.Status != nil {
+		return *m.Status
+	}
+	return 0
+}
+
+func (m *ErrorMessage) GetCode() string {
+	if m != nil && m.Code != nil {
+		return *m.Code
+	}
+	return ""
+}
+
+func (m *ErrorMessage) GetMessage() string {
+	if m != nil && m.Message != nil {
+		return *m.Message
+	}
+	return ""
+}
+
+func (m *ErrorMessage) GetFields() []*FieldErrorMessage {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func (m *ErrorMessage) GetRequestId() string {
+	if m != nil && m.RequestId != nil {
+		return *m.RequestId
+	}
+	return ""
+}
+
+type FieldErrorMessage struct {
+	Field            *string `protobuf:"bytes,1,opt,name=field" json:"field,omitempty"`
+	Rule             *string `protobuf:"bytes,2,opt,name=rule" json:"rule,omitempty"`
+	Message          *string `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *FieldErrorMessage) Reset()         { *m = FieldErrorMessage{} }
+func (m *FieldErrorMessage) String() string { return proto.CompactTextString(m) }
+func (*FieldErrorMessage) ProtoMessage()    {}
+
+func (m *FieldErrorMessage) GetField() string {
+	if m != nil && m.Field != nil {
+		return *m.Field
+	}
+	return ""
+}
+
+func (m *FieldErrorMessage) GetRule() string {
+	if m != nil && m.Rule != nil {
+		return *m.Rule
+	}
+	return ""
+}
+
+func (m *FieldErrorMessage) GetMessage() string {
+	if m != nil && m.Message != nil {
+		return *m.Message
+	}
+	return ""
+}
+
+func error_message(e *api.Error) *ErrorMessage {
+	m := &ErrorMessage{
+		Status: proto.Int32(int32(e.Status)),
+		Code:   proto.String(e.Code),
+	}
+	if e.Message != "" {
+		m.Message = proto.String(e.Message)
+	}
+	if e.RequestId != "" {
+		m.RequestId = proto.String(e.RequestId)
+	}
+	for _, f := range e.Fields {
+		m.Fields = append(m.Fields, &FieldErrorMessage{
+			Field:   proto.String(f.Field),
+			Rule:    proto.String(f.Rule),
+			Message: proto.String(f.Message),
+		})
+	}
+	return m
+}
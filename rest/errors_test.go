@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bmizerany/assert"
+	"github.com/golang/protobuf/proto"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func render_error(accept string, e *api.Error) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", accept)
+	req.Header.Set(api.RequestIdHeader, "req-1")
+	resp := httptest.NewRecorder()
+	RenderError(resp, req, e)
+	return resp
+}
+
+func TestRenderErrorJSON(t *testing.T) {
+	resp := render_error("application/json", api.NewError(http.StatusNotFound, `error-"quoted"`))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+
+	result := api.Error{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, `error-"quoted"`, result.Code)
+	assert.Equal(t, "Not Found", result.Message)
+	assert.Equal(t, "req-1", result.RequestId)
+
+	// nothing acceptable
+	resp = render_error("image/png", api.NewError(http.StatusNotFound, "error-not-found"))
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+}
+
+func TestRenderErrorProtobufAndHtml(t *testing.T) {
+	e := api.ToError(&api.ValidationError{Fields: []api.FieldError{{Field: "name", Rule: "required", Message: "<missing>"}}}, 0)
+
+	resp := render_error("application/protobuf", e)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "application/protobuf", resp.Header().Get("Content-Type"))
+	message := &ErrorMessage{}
+	assert.Equal(t, nil, proto.Unmarshal(resp.Body.Bytes(), message))
+	assert.Equal(t, api.ErrInvalidInput.Error(), message.GetCode())
+	assert.Equal(t, "name", message.Fields[0].GetField())
+
+	resp = render_error("text/html", e)
+	assert.Equal(t, "text/html", resp.Header().Get("Content-Type"))
+	assert.Equal(t, true, strings.Contains(resp.Body.String(), "&lt;missing&gt;"))
+
+	resp = render_error("text/plain", e)
+	assert.Equal(t, "text/plain", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "error-invalid-input: Bad Request\nname: <missing>\nrequest_id: req-1\n", resp.Body.String())
+}
+
+func TestTypedHandlerApiError(t *testing.T) {
+	spec := api.MethodSpec{
+		UrlRoute:   "/teapot",
+		HttpMethod: api.GET,
+	}
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetTypedHandler("test", spec, func(ctx auth.Context, req *Request) (interface{}, error) {
+		return nil, api.NewError(http.StatusConflict, "error-conflict")
+	}))
+
+	req, _ := http.NewRequest("GET", "/teapot", nil)
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	result := api.Error{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "error-conflict", result.Code)
+	assert.Equal(t, http.StatusConflict, result.Status)
+
+	assert.Equal(t, "other", api.ToError(errors.New("other"), http.StatusTeapot).Code)
+	assert.Equal(t, http.StatusTeapot, api.ToError(errors.New("other"), http.StatusTeapot).Status)
+}
@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/qorio/omni/metrics"
+	"strconv"
+)
+
+var (
+	request_count = metrics.NewCounter("rest_requests_total",
+		"Requests served by the rest engine.", "service_method", "route", "method", "status")
+	request_latency = metrics.NewHistogram("rest_request_duration_seconds",
+		"Latency of the requests served by the rest engine.", nil, "service_method", "route", "method")
+	request_panics = metrics.NewCounter("rest_request_panics_total",
+		"Panics recovered while serving requests.")
+
+	sse_clients = metrics.NewGauge("rest_sse_clients",
+		"Clients connected to server sent event channels.")
+	sse_messages = metrics.NewCounter("rest_sse_messages_total",
+		"Messages pushed to the clients of server sent event channels.")
+
+	webhook_deliveries = metrics.NewCounter("rest_webhook_deliveries_total",
+		"Webhook callbacks by result, which is the status class or error.", "result")
+	webhook_latency = metrics.NewHistogram("rest_webhook_delivery_duration_seconds",
+		"Latency of the webhook callbacks.", nil)
+)
+
+func record_request(entry *AccessLogEntry) {
+	method := ""
+	if entry.ServiceMethod != nil {
+		method = strconv.Itoa(int(*entry.ServiceMethod))
+	}
+	request_count.With(method, entry.Route, entry.Method, status_class(entry.Status)).Inc()
+	request_latency.With(method, entry.Route, entry.Method).Observe(entry.Latency.Seconds())
+	if entry.Panic != "" {
+		request_panics.With().Inc()
+	}
+}
+
+func status_class(status int) string {
+	return fmt.Sprintf("%dxx", status/100)
+}
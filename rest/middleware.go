@@ -0,0 +1,229 @@
+package rest
+
+import (
+	"github.com/gorilla/context"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// A middleware wraps the handler of a bound method to add behavior like logging, metrics
+// or rate limiting.  The matched method is available from the request via GetServiceMethod
+// and GetMethodSpec.  The middlewares run in two phases: the ones added with Use, UsePrefix
+// and UseMethod before the authentication, and the ones added with UseAuthenticated,
+// UsePrefixAuthenticated and UseMethodAuthenticated after it, when the auth context of the
+// authenticated methods is available via GetAuthContext.
+type Middleware func(Handler) Handler
+
+type context_key int
+
+const (
+	method_spec_key context_key = iota
+	service_method_key
+	auth_context_key
+	access_log_key
+)
+
+type prefix_middleware struct {
+	prefix      string
+	middlewares []Middleware
+}
+
+type middleware_phase struct {
+	global   []Middleware
+	prefixes []prefix_middleware
+	methods  map[api.ServiceMethod][]Middleware
+}
+
+type middlewares struct {
+	lock   sync.RWMutex
+	before middleware_phase
+	after  middleware_phase
+}
+
+func (this *middleware_phase) use_method(method api.ServiceMethod, m []Middleware) {
+	if this.methods == nil {
+		this.methods = make(map[api.ServiceMethod][]Middleware)
+	}
+	this.methods[method] = append(this.methods[method], m...)
+}
+
+// Adds middlewares for all the bound methods.  Middlewares run in the order they are
+// added: the global ones first, then the ones of matching route prefixes, then the ones
+// of the method.  The middlewares run before the authentication, so that e.g. CORS
+// preflights, rate limiting and compression apply to the requests without a valid token.
+func (this *engine) Use(m ...Middleware) {
+	this.middlewares.lock.Lock()
+	defer this.middlewares.lock.Unlock()
+	this.middlewares.before.global = append(this.middlewares.before.global, m...)
+}
+
+// Adds middlewares for the methods whose url route starts with the prefix.
+func (this *engine) UsePrefix(prefix string, m ...Middleware) {
+	this.middlewares.lock.Lock()
+	defer this.middlewares.lock.Unlock()
+	this.middlewares.before.prefixes = append(this.middlewares.before.prefixes, prefix_middleware{prefix, m})
+}
+
+// Adds middlewares for the service method of the engine's spec.
+func (this *engine) UseMethod(method api.ServiceMethod, m ...Middleware) {
+	this.middlewares.lock.Lock()
+	defer this.middlewares.lock.Unlock()
+	this.middlewares.before.use_method(method, m)
+}
+
+// Same as Use, for middlewares that run once the request is authenticated, e.g. to act on
+// the identity or the scopes of the caller.  They run for the methods without auth scope
+// as well, after the ones added with Use.
+func (this *engine) UseAuthenticated(m ...Middleware) {
+	this.middlewares.lock.Lock()
+	defer this.middlewares.lock.Unlock()
+	this.middlewares.after.global = append(this.middlewares.after.global, m...)
+}
+
+// Same as UsePrefix, once the request is authenticated.
+func (this *engine) UsePrefixAuthenticated(prefix string, m ...Middleware) {
+	this.middlewares.lock.Lock()
+	defer this.middlewares.lock.Unlock()
+	this.middlewares.after.prefixes = append(this.middlewares.after.prefixes, prefix_middleware{prefix, m})
+}
+
+// Same as UseMethod, once the request is authenticated.
+func (this *engine) UseMethodAuthenticated(method api.ServiceMethod, m ...Middleware) {
+	this.middlewares.lock.Lock()
+	defer this.middlewares.lock.Unlock()
+	this.middlewares.after.use_method(method, m)
+}
+
+// The middlewares of the phase for the method, outermost first.
+func (this *engine) middlewares_for(phase *middleware_phase, ep *ServiceMethodImpl, req *http.Request) []Middleware {
+	this.middlewares.lock.RLock()
+	defer this.middlewares.lock.RUnlock()
+	chain := append([]Middleware{}, phase.global...)
+	for _, p := range phase.prefixes {
+		if strings.HasPrefix(ep.Api.UrlRoute, p.prefix) {
+			chain = append(chain, p.middlewares...)
+		}
+	}
+	if method, has := GetServiceMethod(req); has {
+		chain = append(chain, phase.methods[method]...)
+	}
+	return chain
+}
+
+// Wraps the handler with the middlewares of the phase.  The chain is built for each request
+// so that middlewares added after binding apply as well.
+func (this *engine) with_phase(phase *middleware_phase, ep *ServiceMethodImpl, handler Handler) Handler {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		chain := this.middlewares_for(phase, ep, req)
+		h := handler
+		for i := len(chain) - 1; i >= 0; i-- {
+			h = chain[i](h)
+		}
+		h(resp, req)
+	}
+}
+
+// Wraps the handler with the middlewares of both phases, for the methods without auth scope.
+func (this *engine) with_middlewares(ep *ServiceMethodImpl, handler Handler) Handler {
+	return this.with_phase(&this.middlewares.before, ep, this.with_phase(&this.middlewares.after, ep, handler))
+}
+
+// Wraps the authenticated handler with the middlewares before the authentication.
+func (this *engine) with_auth_middlewares(ep *ServiceMethodImpl, handler Handler) Handler {
+	return this.with_phase(&this.middlewares.before, ep, handler)
+}
+
+// Makes the auth context of the authenticated request available to the middlewares after
+// the authentication, which then wrap the handler.
+func (this *engine) with_auth_context(ep *ServiceMethodImpl, handler auth.HttpHandler) auth.HttpHandler {
+	return func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		context.Set(req, auth_context_key, ctx)
+		if entry := access_log_entry(req); entry != nil && ctx != nil {
+			entry.Subject = ctx.GetString(AccessLogSubjectClaim)
+		}
+		this.with_phase(&this.middlewares.after, ep, func(resp http.ResponseWriter, req *http.Request) {
+			handler(ctx, resp, req)
+		})(resp, req)
+	}
+}
+
+// Finds the key of the method in the engine's spec by its route and http methods.
+func (this *engine) service_method(m api.MethodSpec) (api.ServiceMethod, bool) {
+	if this.spec == nil {
+		return 0, false
+	}
+	for key, spec := range *this.spec {
+		if spec.UrlRoute == m.UrlRoute && same_methods(http_methods(spec), http_methods(m)) {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+func http_methods(m api.MethodSpec) []api.HttpMethod {
+	methods := []api.HttpMethod{}
+	if m.HttpMethod != "" {
+		methods = append(methods, m.HttpMethod)
+	}
+	return append(methods, m.HttpMethods...)
+}
+
+func same_methods(a, b []api.HttpMethod) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, _ := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Records the spec and the key of the method handling the request so that the content
+// negotiation and the middlewares can take them into account.
+func (this *engine) with_method_spec(ep *ServiceMethodImpl, handler Handler) Handler {
+	method, has_method := this.service_method(ep.Api)
+	return func(resp http.ResponseWriter, req *http.Request) {
+		context.Set(req, method_spec_key, &ep.Api)
+		if has_method {
+			context.Set(req, service_method_key, method)
+		}
+		if entry := access_log_entry(req); entry != nil {
+			entry.Route = ep.Api.UrlRoute
+			if has_method {
+				entry.ServiceMethod = &method
+			}
+		}
+		handler(resp, req)
+	}
+}
+
+// The method spec of the route that matched the request, if the request is being
+// handled by a method bound to the engine.
+func GetMethodSpec(req *http.Request) *api.MethodSpec {
+	if v, ok := context.GetOk(req, method_spec_key); ok {
+		return v.(*api.MethodSpec)
+	}
+	return nil
+}
+
+// The key of the method handling the request, if the method is in the engine's spec.
+func GetServiceMethod(req *http.Request) (api.ServiceMethod, bool) {
+	if v, ok := context.GetOk(req, service_method_key); ok {
+		return v.(api.ServiceMethod), true
+	}
+	return 0, false
+}
+
+// The auth context of the request if the method handling it requires authentication.
+func GetAuthContext(req *http.Request) auth.Context {
+	if v, ok := context.GetOk(req, auth_context_key); ok {
+		ctx, _ := v.(auth.Context)
+		return ctx
+	}
+	return nil
+}
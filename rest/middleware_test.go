@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"bytes"
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func recording(name string, calls *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			*calls = append(*calls, name)
+			next(resp, req)
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	e := greeting_engine()
+	calls := []string{}
+	e.UseMethod(PostGreeting, recording("method", &calls))
+	e.UsePrefix("/greeting", recording("prefix", &calls))
+	e.UsePrefix("/other", recording("other", &calls))
+	e.Use(recording("global1", &calls), recording("global2", &calls))
+	e.UseAuthenticated(recording("authenticated", &calls))
+	e.Use(func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			method, has := GetServiceMethod(req)
+			assert.Equal(t, true, has)
+			calls = append(calls, greetings[method].UrlRoute)
+			next(resp, req)
+			calls = append(calls, "done")
+		}
+	})
+
+	req, _ := http.NewRequest("POST", "/greeting/1", bytes.NewBufferString(`{"name":"a"}`))
+	req.Header.Set("Accept-Language", "en")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"global1", "global2", "/greeting/{id}", "prefix", "method", "authenticated", "done"}, calls)
+}
+
+func TestMiddlewareShortCircuitAndAuthContext(t *testing.T) {
+	e := greeting_engine()
+	has_context := false
+	e.UseMethodAuthenticated(GetGreeting, func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			has_context = GetAuthContext(req) != nil
+			next(resp, req)
+		}
+	})
+	e.UseMethod(GetGreeting, func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, nil, GetAuthContext(req))
+			next(resp, req)
+		}
+	})
+	e.UsePrefix("/fail", func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			RenderError(resp, req, api.NewError(http.StatusTooManyRequests, "error-rate-limited"))
+		}
+	})
+
+	req, _ := http.NewRequest("GET", "/greeting", nil)
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, true, has_context)
+
+	req, _ = http.NewRequest("GET", "/fail", nil)
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestMiddlewareBeforeAuth(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	spec := api.ServiceMethods{
+		0: api.MethodSpec{UrlRoute: "/account", HttpMethod: api.GET, AuthScope: "read"},
+	}
+	e := NewEngine(&spec, auth.Init(auth.Settings{
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+	}), nil)
+	e.Bind(SetAuthenticatedHandler("test", spec[0], func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		t.Fatal("not authenticated")
+	}))
+	limited := false
+	e.Use(func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			resp.Header().Set("Access-Control-Allow-Origin", "*")
+			if limited {
+				RenderError(resp, req, api.NewError(http.StatusTooManyRequests, "error-rate-limited"))
+				return
+			}
+			next(resp, req)
+		}
+	})
+
+	req, _ := http.NewRequest("GET", "/account", nil)
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "*", resp.Header().Get("Access-Control-Allow-Origin"))
+
+	limited = true
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+func TestMiddlewareAfterAuth(t *testing.T) {
+	key := func(*http.Request) []byte { return []byte("test") }
+	service := auth.Init(auth.Settings{
+		SignKeyFromHttpRequest:   key,
+		VerifyKeyFromHttpRequest: key,
+		IsAuthOn:                 func() bool { return true },
+	})
+	spec := api.ServiceMethods{
+		0: api.MethodSpec{UrlRoute: "/account", HttpMethod: api.GET, AuthScope: "read"},
+	}
+	e := NewEngine(&spec, service, nil)
+	e.Bind(SetAuthenticatedHandler("test", spec[0], func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		resp.Write([]byte(ctx.GetString("sub")))
+	}))
+	subjects := []string{}
+	e.UseMethodAuthenticated(0, func(next Handler) Handler {
+		return func(resp http.ResponseWriter, req *http.Request) {
+			subject := GetAuthContext(req).GetString("sub")
+			subjects = append(subjects, subject)
+			if subject != "alice" {
+				RenderError(resp, req, api.NewError(http.StatusForbidden, "error-forbidden"))
+				return
+			}
+			next(resp, req)
+		}
+	})
+	get := func(subject string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/account", nil)
+		if subject != "" {
+			token, _ := service.SignedStringForHttpRequest(service.NewToken().
+				Add("test/@scopes", "read").Add("sub", subject), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		e.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("alice")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "alice", resp.Body.String())
+	assert.Equal(t, http.StatusForbidden, get("mallory").Code)
+	assert.Equal(t, http.StatusUnauthorized, get("").Code)
+	assert.Equal(t, []string{"alice", "mallory"}, subjects)
+}
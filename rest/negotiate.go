@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"github.com/qorio/omni/api"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// A media range of the Accept header, as in RFC 7231 section 5.3.2
+type media_range struct {
+	mtype   string
+	subtype string
+	params  map[string]string
+	q       float64
+}
+
+// Parses the Accept header into media ranges.  Malformed ranges are skipped.
+func parse_accept(header string) []media_range {
+	ranges := []media_range{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, ";")
+		t := strings.ToLower(strings.TrimSpace(fields[0]))
+		slash := strings.Index(t, "/")
+		if slash <= 0 || slash == len(t)-1 {
+			continue
+		}
+		r := media_range{
+			mtype:   t[:slash],
+			subtype: t[slash+1:],
+			params:  make(map[string]string),
+			q:       1.,
+		}
+		if r.mtype == "*" && r.subtype != "*" {
+			continue
+		}
+		for _, p := range fields[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(kv[0]))
+			value := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			if key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+				continue
+			}
+			r.params[key] = value
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// Returns how specific the match of the content type is, or -1 if there is no match.
+func (this media_range) match(contentType string) int {
+	slash := strings.Index(contentType, "/")
+	if slash < 0 {
+		return -1
+	}
+	mtype, subtype := contentType[:slash], contentType[slash+1:]
+	switch {
+	case this.mtype == "*":
+		return 0
+	case this.mtype != mtype:
+		return -1
+	case this.subtype == "*":
+		return 1
+	case this.subtype != subtype:
+		return -1
+	case len(this.params) > 0:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// Selects the offered content type with the highest quality in the Accept header.  The
+// quality of an offer is the one of the most specific matching range.  Ties are resolved
+// by the order of the offers.
+func negotiate(accept string, offers []string) (string, bool) {
+	ranges := parse_accept(accept)
+	best, best_q := "", 0.
+	for _, offer := range offers {
+		specificity, q := -1, 0.
+		for _, r := range ranges {
+			if s := r.match(offer); s > specificity {
+				specificity, q = s, r.q
+			}
+		}
+		if specificity >= 0 && q > best_q {
+			best, best_q = offer, q
+		}
+	}
+	return best, best_q > 0
+}
+
+// Returns the media type without any parameters like charset, in lower case.
+func media_type(header string) string {
+	if t, _, err := mime.ParseMediaType(header); err == nil {
+		return t
+	}
+	return strings.ToLower(strings.TrimSpace(strings.Split(header, ";")[0]))
+}
+
+// The content types that can be rendered for the method.  If the method declares its
+// content types only those are offered; otherwise all the registered codecs are.
+func response_offers(m *api.MethodSpec) []string {
+	offers := []string{}
+	if m != nil && len(m.ContentTypes) > 0 {
+		for _, ct := range m.ContentTypes {
+			if _, has := GetCodec(ct); has && ct != "" {
+				offers = append(offers, ct)
+			}
+		}
+		return offers
+	}
+	for _, ct := range CodecContentTypes() {
+		if ct != ContentTypeJSON {
+			offers = append(offers, ct)
+		}
+	}
+	return append([]string{ContentTypeJSON}, offers...)
+}
+
+// Negotiates the content type of the response from the Accept header.  Without an Accept
+// header the content type of the request is used, if it can be rendered.
+func negotiate_response(req *http.Request, m *api.MethodSpec) (string, error) {
+	return negotiate_offers(req, response_offers(m))
+}
+
+func negotiate_offers(req *http.Request, offers []string) (string, error) {
+	if len(offers) == 0 {
+		return "", ErrNotAcceptable
+	}
+	accept := req.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		t := content_type_for_request(req)
+		for _, offer := range offers {
+			if offer == t {
+				return t, nil
+			}
+		}
+		return offers[0], nil
+	}
+	if t, ok := negotiate(accept, offers); ok {
+		return t, nil
+	}
+	return "", ErrNotAcceptable
+}
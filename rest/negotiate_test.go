@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"bytes"
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var offers = []string{"application/json", "application/protobuf", "text/plain"}
+
+func TestNegotiate(t *testing.T) {
+	browser := "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8"
+	ct, ok := negotiate(browser, offers)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "application/json", ct)
+
+	ct, ok = negotiate("application/json; charset=utf-8", offers)
+	assert.Equal(t, "application/json", ct)
+
+	ct, ok = negotiate("text/*;q=0.5, application/protobuf", offers)
+	assert.Equal(t, "application/protobuf", ct)
+
+	ct, ok = negotiate("*/*;q=0.1, text/plain", offers)
+	assert.Equal(t, "text/plain", ct)
+
+	// The most specific range determines the quality
+	ct, ok = negotiate("application/*, application/json;q=0", offers)
+	assert.Equal(t, "application/protobuf", ct)
+
+	_, ok = negotiate("image/png, text/html", offers)
+	assert.Equal(t, false, ok)
+
+	_, ok = negotiate("*/*;q=0", offers)
+	assert.Equal(t, false, ok)
+}
+
+func TestContentTypeForRequest(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", "application/JSON; charset=UTF-8")
+	assert.Equal(t, "application/json", content_type_for_request(req))
+	assert.Equal(t, true, JSONContentType(req))
+
+	// Without an Accept header the response uses the type of the request.
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	assert.Equal(t, "text/plain", content_type_for_response(req))
+}
+
+func TestNotAcceptable(t *testing.T) {
+	spec := api.MethodSpec{
+		UrlRoute:     "/greeting",
+		HttpMethod:   api.POST,
+		ContentTypes: []string{"application/json"},
+		RequestBody: func(req *http.Request) interface{} {
+			return &greeting{}
+		},
+	}
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetTypedHandler("test", spec, func(ctx auth.Context, req *Request) (interface{}, error) {
+		return req.Body, nil
+	}))
+
+	req, _ := http.NewRequest("POST", "/greeting", bytes.NewBufferString(`{"name":"x"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "text/plain")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotAcceptable, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/greeting", bytes.NewBufferString(`{"name":"x"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "text/html,*/*;q=0.8")
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+}
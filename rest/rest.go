@@ -1,16 +1,13 @@
 package rest
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/gorilla/mux"
 	"github.com/qorio/omni/api"
 	"github.com/qorio/omni/auth"
-	"io"
-	"io/ioutil"
+	"github.com/qorio/omni/logging"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -25,111 +22,26 @@ const (
 )
 
 var (
-	string_marshaler = func(contentType string, resp http.ResponseWriter, typed interface{}, noHeader ...nht) error {
-		if str, ok := typed.(*string); ok {
-			if len(noHeader) == 0 {
-				resp.Header().Add("Content-Type", contentType)
-			}
-			resp.Write([]byte(*str))
-			return nil
-		} else {
-			return errors.New("wrong-type-expects-str-ptr")
-		}
-	}
-
-	string_unmarshaler = func(body io.ReadCloser, typed interface{}) error {
-		if _, ok := typed.(*string); !ok {
-			return errors.New("wrong-type-expects-str-ptr")
-		}
-		if buff, err := ioutil.ReadAll(body); err == nil {
-			ptr := typed.(*string)
-			*ptr = string(buff)
-			return nil
-		} else {
-			return err
-		}
-	}
-
-	json_marshaler = func(contentType string, resp http.ResponseWriter, typed interface{}, noHeader ...nht) error {
-		if buff, err := json.Marshal(typed); err == nil {
-			if len(noHeader) == 0 {
-				resp.Header().Add("Content-Type", contentType)
-			}
-			resp.Write(buff)
-			return nil
-		} else {
-			return err
-		}
-	}
-
-	json_unmarshaler = func(body io.ReadCloser, typed interface{}) error {
-		dec := json.NewDecoder(body)
-		return dec.Decode(typed)
-	}
-
-	json_logging_unmarshaler = func(body io.ReadCloser, typed interface{}) error {
-		buff, err := ioutil.ReadAll(body)
-		if err != nil {
-			return err
-		}
-		glog.V(100).Infoln("Unmarshal [", string(buff), "]")
-		return json.Unmarshal(buff, typed)
-	}
-
-	proto_marshaler = func(contentType string, resp http.ResponseWriter, any interface{}, noHeader ...nht) error {
-		typed, ok := any.(proto.Message)
-		if !ok {
-			return ErrIncompatibleType
-		}
-		if buff, err := proto.Marshal(typed); err == nil {
-			resp.Header().Add("Content-Type", contentType)
-			resp.Write(buff)
-			return nil
-		} else {
-			return err
-		}
-	}
-
-	proto_unmarshaler = func(body io.ReadCloser, any interface{}) error {
-		typed, ok := any.(proto.Message)
-		if !ok {
-			return ErrIncompatibleType
-		}
-		buff, err := ioutil.ReadAll(body)
-		if err != nil {
-			return err
-		}
-		return proto.Unmarshal(buff, typed)
-	}
-
-	marshalers = map[string]func(string, http.ResponseWriter, interface{}, ...nht) error{
-		"":                     json_marshaler,
-		"application/json":     json_marshaler,
-		"application/protobuf": proto_marshaler,
-		"text/plain":           string_marshaler,
-		"text/html":            nil,
-	}
+	ApiDocsPath = "/api-docs"
 
-	unmarshalers = map[string]func(io.ReadCloser, interface{}) error{
-		"":                     json_logging_unmarshaler,
-		"application/json":     json_logging_unmarshaler,
-		"application/protobuf": proto_unmarshaler,
-		"text/plain":           string_unmarshaler,
-		"text/html":            nil,
-	}
+	logger = logging.For("rest")
 )
 
 type ServiceMethodImpl struct {
 	Api                  api.MethodSpec // note this is by copy -- so that behavior is deterministic after initialization
 	Handler              Handler
 	AuthenticatedHandler auth.HttpHandler
+	TypedHandler         TypedHandler
 	ServiceId            string
 }
 
 func SetHandler(m api.MethodSpec, h Handler) *ServiceMethodImpl {
-	if m.AuthScope != "" {
+	if m.RequiresAuth() {
 		panic(errors.New(fmt.Sprintf("Method %s has oauth scopes but binding to unauthed handler.", m)))
 	}
+	if m.Authorize != nil {
+		panic(errors.New(fmt.Sprintf("Method %s has an authorize hook but binding to unauthed handler.", m.UrlRoute)))
+	}
 	return &ServiceMethodImpl{
 		Api:     m,
 		Handler: h,
@@ -137,7 +49,7 @@ func SetHandler(m api.MethodSpec, h Handler) *ServiceMethodImpl {
 }
 
 func SetAuthenticatedHandler(serviceId string, m api.MethodSpec, h auth.HttpHandler) *ServiceMethodImpl {
-	if m.AuthScope == "" {
+	if !m.RequiresAuth() {
 		panic(errors.New(fmt.Sprintf("Method %s has no oauth scopes but binding to authenticated handler.", m)))
 	}
 	return &ServiceMethodImpl{
@@ -155,6 +67,7 @@ type engine struct {
 	done_chan   chan bool
 	webhooks    WebhookManager
 	sseChannels map[string]*sseChannel
+	middlewares middlewares
 	lock        sync.Mutex
 	running     bool
 }
@@ -173,8 +86,15 @@ func NewEngine(spec *api.ServiceMethods, auth auth.Service, webhooks WebhookMana
 }
 
 func (this *engine) ServeHTTP(resp http.ResponseWriter, request *http.Request) {
-	defer this.lock.Unlock()
+	this.start()
+	this.serve_logged(resp, request, this.router)
+}
+
+// Starts the event loop once.  The requests are served concurrently, so the lock is not
+// held while serving.
+func (this *engine) start() {
 	this.lock.Lock()
+	defer this.lock.Unlock()
 
 	if !this.running {
 		// Also start listening on the event channel for any webhook calls
@@ -187,7 +107,7 @@ func (this *engine) ServeHTTP(resp http.ResponseWriter, request *http.Request) {
 
 				case done := <-this.done_chan:
 					if done {
-						glog.Infoln("REST engine event channel stopped.")
+						logger.Info("event-channel-stopped")
 						return
 					}
 				}
@@ -195,8 +115,6 @@ func (this *engine) ServeHTTP(resp http.ResponseWriter, request *http.Request) {
 		}()
 		this.running = true
 	}
-
-	this.router.ServeHTTP(resp, request)
 }
 
 func (this *engine) GetUrlParameter(req *http.Request, key string) string {
@@ -236,45 +154,44 @@ func (this *engine) GetPostForm(req *http.Request, m api.FormParams) (api.FormPa
 
 func (this *engine) GetUrlQueries(req *http.Request, m api.UrlQueries) (api.UrlQueries, error) {
 	result := make(api.UrlQueries)
+	invalid := &api.ValidationError{}
 	for key, default_value := range m {
 		actual := this.GetUrlParameter(req, key)
 		if actual != "" {
+			if default_value == nil {
+				result[key] = actual
+				continue
+			}
 			// Check the type and do conversion
+			var err error
 			switch reflect.TypeOf(default_value).Kind() {
 			case reflect.Bool:
-				if v, err := strconv.ParseBool(actual); err != nil {
-					return nil, err
-				} else {
-					result[key] = v
-				}
+				result[key], err = strconv.ParseBool(actual)
 			case reflect.String:
 				result[key] = actual
 			case reflect.Int:
-				if v, err := strconv.Atoi(actual); err != nil {
-					return nil, err
-				} else {
-					result[key] = v
-				}
+				result[key], err = strconv.Atoi(actual)
 			case reflect.Float32:
-				if v, err := strconv.ParseFloat(actual, 32); err != nil {
-					return nil, err
-				} else {
-					result[key] = v
-				}
+				result[key], err = strconv.ParseFloat(actual, 32)
 			case reflect.Float64:
-				if v, err := strconv.ParseFloat(actual, 64); err != nil {
-					return nil, err
-				} else {
-					result[key] = v
-				}
+				result[key], err = strconv.ParseFloat(actual, 64)
 			default:
 				return nil, ErrNotSupportedUrlParameterType
 			}
-
+			if err != nil {
+				invalid.Add(api.FieldError{
+					Field:   key,
+					Rule:    "type",
+					Message: fmt.Sprintf("must be of type %s", reflect.TypeOf(default_value).Kind()),
+				})
+			}
 		} else {
 			result[key] = default_value
 		}
 	}
+	if err := invalid.OrNil(); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -282,44 +199,92 @@ func (this *engine) Handle(path string, handler http.Handler) {
 	this.router.Handle(path, handler)
 }
 
+// Mounts the OpenAPI document generated from the engine's service methods at ApiDocsPath.
+func (this *engine) ServeApiDocs(info api.OpenApiInfo) {
+	if this.spec == nil {
+		panic(errors.New("no-service-methods"))
+	}
+	this.router.Handle(ApiDocsPath, this.spec.OpenApi(info)).Methods("GET")
+}
+
 func (this *engine) Bind(endpoints ...*ServiceMethodImpl) {
 	for i, ep := range endpoints {
+		var handler Handler
 		switch {
 		case ep.Handler != nil:
-			this.router.HandleFunc(ep.Api.UrlRoute, ep.Handler).Methods(string(ep.Api.HttpMethod))
+			handler = this.with_middlewares(ep, this.validated(ep, ep.Handler))
 
 		case ep.AuthenticatedHandler != nil:
-			h := this.router.HandleFunc(ep.Api.UrlRoute,
-				this.auth.RequiresAuth(ep.Api.AuthScope, func(token *auth.Token) []string {
-					return strings.Split(token.GetString(ep.ServiceId+"/@scopes"), ",")
-				}, ep.AuthenticatedHandler))
-			if ep.Api.HttpMethod != "" {
-				h.Methods(string(ep.Api.HttpMethod))
-			}
-			if len(ep.Api.HttpMethods) > 0 {
-				s := []string{}
-				for _, m := range ep.Api.HttpMethods {
-					s = append(s, string(m))
+			handler = this.with_auth_middlewares(ep, this.requires_auth(ep, this.with_auth_context(ep, this.authorized(ep, this.validated_auth(ep, ep.AuthenticatedHandler)))))
+
+		case ep.TypedHandler != nil:
+			if ep.Api.RequiresAuth() {
+				handler = this.with_auth_middlewares(ep, this.requires_auth(ep, this.with_auth_context(ep, this.authorized(ep, this.typed_handler(ep)))))
+			} else {
+				if ep.Api.Authorize != nil {
+					panic(errors.New(fmt.Sprintf("Method %s has an authorize hook but no oauth scopes.", ep.Api.UrlRoute)))
 				}
-				h.Methods(s...)
+				typed := this.typed_handler(ep)
+				handler = this.with_middlewares(ep, func(resp http.ResponseWriter, req *http.Request) {
+					typed(nil, resp, req)
+				})
 			}
 
-		case ep.Handler == nil && ep.AuthenticatedHandler == nil:
+		default:
 			panic(errors.New(fmt.Sprintf("No implementation for REST endpoint[%d]: %s", i, ep)))
 		}
+		bind_methods(this.router.HandleFunc(ep.Api.UrlRoute, this.with_method_spec(ep, handler)), ep.Api)
+
+		if err := ep.Api.Constraints.Check(); err != nil {
+			panic(errors.New(fmt.Sprintf("Bad constraint: %s", err)))
+		}
+		if ep.Api.RequestBody != nil {
+			if err := api.CheckValidateTags(ep.Api.RequestBody); err != nil {
+				panic(errors.New(fmt.Sprintf("Bad validate tag: %s", err)))
+			}
+		}
 
 		// check the content type
 		for _, ct := range ep.Api.ContentTypes {
-			if _, has := marshalers[ct]; !has {
-				panic(errors.New(fmt.Sprintf("Bad content type: %s", ct)))
-			}
-			if _, has := unmarshalers[ct]; !has {
+			if _, has := GetCodec(ct); !has {
 				panic(errors.New(fmt.Sprintf("Bad content type: %s", ct)))
 			}
 		}
 	}
 }
 
+// The tokens must be meant for the service of the method, if the auth service checks the audience,
+// and have the any-of and all-of scopes of the method.
+func (this *engine) requires_auth(ep *ServiceMethodImpl, handler auth.HttpHandler) func(http.ResponseWriter, *http.Request) {
+	get_scopes := func(token *auth.Token) []string {
+		return strings.Split(token.GetString(ep.ServiceId+"/@scopes"), ",")
+	}
+	if service, ok := this.auth.(auth.RequirementService); ok {
+		requirement := auth.Requirement{AnyOf: ep.Api.AnyScopes(), AllOf: ep.Api.AllOfScopes}
+		return service.RequiresScopes(ep.ServiceId, requirement, get_scopes, handler)
+	}
+	if len(ep.Api.AnyOfScopes) > 0 || len(ep.Api.AllOfScopes) > 0 {
+		panic(errors.New(fmt.Sprintf("Method %s has any-of or all-of scopes but the auth service cannot check them.", ep.Api.UrlRoute)))
+	}
+	if service, ok := this.auth.(auth.AudienceService); ok && ep.ServiceId != "" {
+		return service.RequiresAuthForAudience(ep.ServiceId, ep.Api.AuthScope, get_scopes, handler)
+	}
+	return this.auth.RequiresAuth(ep.Api.AuthScope, get_scopes, handler)
+}
+
+func bind_methods(h *mux.Route, m api.MethodSpec) {
+	if m.HttpMethod != "" {
+		h.Methods(string(m.HttpMethod))
+	}
+	if len(m.HttpMethods) > 0 {
+		s := []string{}
+		for _, m := range m.HttpMethods {
+			s = append(s, string(m))
+		}
+		h.Methods(s...)
+	}
+}
+
 func JSONContentType(req *http.Request) bool {
 	return "application/json" == content_type_for_request(req)
 }
@@ -336,8 +301,8 @@ func GetContentType(req *http.Request) *string {
 func content_type_for_request(req *http.Request) string {
 	t := "application/json"
 
-	if req.Method == "POST" || req.Method == "PUT" {
-		t = req.Header.Get("Content-Type")
+	if req.Method == "POST" || req.Method == "PUT" || req.Method == "PATCH" {
+		t = media_type(req.Header.Get("Content-Type"))
 	}
 	switch t {
 	case "*/*":
@@ -349,77 +314,42 @@ func content_type_for_request(req *http.Request) string {
 	}
 }
 
+// The content type negotiated for the response.  If nothing is acceptable JSON is
+// returned since this is mostly used for rendering errors.
 func content_type_for_response(req *http.Request) string {
-	t := req.Header.Get("Accept")
-	switch t {
-	case "*/*":
+	t, err := negotiate_response(req, GetMethodSpec(req))
+	if err != nil {
 		return "application/json"
-	case "":
-		return content_type_for_request(req) // use the same content type as the request if no accept header
-	default:
-		return t
-	}
-}
-
-var ErrorRenderer = func(resp http.ResponseWriter, req *http.Request, message string, code int) (err error) {
-	// First look for accept content type in the header
-	ct := content_type_for_response(req)
-	switch ct {
-	case "application/json":
-		resp.Write([]byte(fmt.Sprintf("{ \"error\": \"%s\" }", message)))
-		return
-	case "application/protobuf":
-		return
-	default:
-		resp.Write([]byte(fmt.Sprintf("<html><body>Error: %s </body></html>", message)))
-		return
 	}
+	return t
 }
 
 func (this *engine) Unmarshal(req *http.Request, typed proto.Message) (err error) {
-	contentType := content_type_for_request(req)
-	if unmarshaler, has := unmarshalers[contentType]; has {
-		return unmarshaler(req.Body, typed)
-	} else {
-		return ErrUnknownContentType
-	}
+	return unmarshal(content_type_for_request(req), req.Body, typed)
 }
 
 func (this *engine) Marshal(req *http.Request, typed proto.Message, resp http.ResponseWriter) (err error) {
-	contentType := content_type_for_response(req)
-	if marshaler, has := marshalers[contentType]; has {
-		return marshaler(contentType, resp, typed)
-	} else {
+	contentType, err := negotiate_response(req, GetMethodSpec(req))
+	if err != nil {
+		return err
+	}
+	if _, has := GetCodec(contentType); !has {
 		return ErrUnknownContentType
 	}
+	return marshal(contentType, resp, typed)
 }
 
 func (this *engine) UnmarshalJSON(req *http.Request, any interface{}) (err error) {
-	contentType := content_type_for_request(req)
-	if unmarshaler, has := unmarshalers[contentType]; has {
-		return unmarshaler(req.Body, any)
-	} else {
-		return ErrUnknownContentType
-	}
+	return unmarshal(content_type_for_request(req), req.Body, any)
 }
 
 func (this *engine) MarshalJSON(req *http.Request, any interface{}, resp http.ResponseWriter) (err error) {
-	if buff, err := json.Marshal(any); err == nil {
-		resp.Header().Add("Content-Type", "application/json")
-		resp.Write(buff)
-		return nil
-	} else {
-		return err
-	}
+	return marshal(ContentTypeJSON, resp, any)
 }
 
-func (this *engine) HandleError(resp http.ResponseWriter, req *http.Request, message string, code int) (err error) {
-	resp.WriteHeader(code)
-	if len(message) > 0 {
-		escaped := strings.Replace(message, "\"", "'", -1)
-		resp.Write([]byte(fmt.Sprintf("{\"error\":\"%s\"}", escaped)))
-	}
-	return
+// Renders the error code with the status.  See RenderError.
+func (this *engine) HandleError(resp http.ResponseWriter, req *http.Request, code string, status int) (err error) {
+	return RenderError(resp, req, api.NewError(status, code))
 }
 
 func (this *engine) EventChannel() chan<- *EngineEvent {
@@ -3,11 +3,16 @@ package rest
 import (
 	"errors"
 	"fmt"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/api"
 	"net/http"
 	"sync"
 )
 
+var (
+	// The event sent to the clients when the server ends an event stream, e.g. on shutdown.
+	SseCloseEvent = "close"
+)
+
 // TODO - return and disconnect client
 func (this *engine) DirectHttpStream(w http.ResponseWriter, r *http.Request) (chan<- interface{}, error) {
 
@@ -34,7 +39,7 @@ func (this *engine) DirectHttpStream(w http.ResponseWriter, r *http.Request) (ch
 			if !open || msg == nil {
 				// If our messageChan was closed, this means that the client has
 				// disconnected.
-				glog.V(100).Infoln("Messages stopped.. Closing http connection")
+				logger.Debug("sse-messages-stopped", "path", r.URL.Path)
 				break
 			}
 
@@ -43,7 +48,7 @@ func (this *engine) DirectHttpStream(w http.ResponseWriter, r *http.Request) (ch
 			default:
 				fmt.Fprintf(w, "event: %s\n", t)
 				fmt.Fprint(w, "data: ")
-				json_marshaler("application/json", w, &msg, no_header)
+				marshal(ContentTypeJSON, w, &msg, no_header)
 				fmt.Fprint(w, "\n\n")
 			case string:
 				fmt.Fprintf(w, "%s\n", msg.(string))
@@ -56,7 +61,7 @@ func (this *engine) DirectHttpStream(w http.ResponseWriter, r *http.Request) (ch
 			f.Flush()
 		}
 		// Done.
-		glog.V(100).Infoln("Finished HTTP request at ", r.URL.Path)
+		logger.Debug("sse-request-finished", "path", r.URL.Path)
 	}()
 
 	return messageChan, nil
@@ -71,9 +76,13 @@ func (this *engine) MergeHttpStream(resp http.ResponseWriter, req *http.Request,
 			// connect the source
 			for {
 				if m, open := <-source; open {
-					sc.messages <- m
+					select {
+					case sc.messages <- m:
+					case <-sc.done:
+						return
+					}
 				} else {
-					glog.Infoln("Source", source, "closed.")
+					logger.Info("sse-source-closed", "source", source)
 					return
 				}
 			}
@@ -83,9 +92,23 @@ func (this *engine) MergeHttpStream(resp http.ResponseWriter, req *http.Request,
 	return nil
 }
 
+// Ends the event streams, sending a close event to the clients.
 func (this *engine) Stop() {
+	this.Drain()
+}
+
+// Ends the event streams so that the server can drain its connections on shutdown.  The
+// clients receive a close event.
+func (this *engine) Drain() {
+	this.lock.Lock()
+	channels := make([]*sseChannel, 0, len(this.sseChannels))
 	for _, s := range this.sseChannels {
-		s.stop <- 1
+		channels = append(channels, s)
+	}
+	this.lock.Unlock()
+
+	for _, s := range channels {
+		s.Stop()
 	}
 }
 
@@ -93,7 +116,7 @@ func (this *engine) deleteSseChannel(key string) {
 	this.lock.Lock()
 	defer this.lock.Unlock()
 	delete(this.sseChannels, key)
-	glog.Infoln("Removed sse channel", key, "count=", len(this.sseChannels))
+	logger.Info("sse-channel-removed", "key", key, "count", len(this.sseChannels))
 }
 
 func (this *engine) StreamChannel(contentType, eventType, key string) (*sseChannel, bool) {
@@ -126,8 +149,9 @@ type sseChannel struct {
 	engine *engine
 	lock   sync.Mutex
 
-	// Send to this to stop
-	stop chan int
+	// Closed when the channel stops
+	done    chan int
+	stopped bool
 
 	clients map[event_client]int
 
@@ -147,7 +171,7 @@ type sseChannel struct {
 }
 
 func (this *sseChannel) Init() *sseChannel {
-	this.stop = make(chan int)
+	this.done = make(chan int)
 	this.clients = make(map[event_client]int)
 	this.newClients = make(chan event_client)
 	this.defunctClients = make(chan event_client)
@@ -156,34 +180,34 @@ func (this *sseChannel) Init() *sseChannel {
 	return this
 }
 
+// Stops the channel and ends the streams of the attached clients.
 func (this *sseChannel) Stop() {
-	glog.V(100).Infoln("Stopping channel", this.Key)
+	logger.Debug("sse-channel-stopping", "key", this.Key)
 
-	if this.stop == nil {
-		glog.V(100).Infoln("Stopped.")
+	this.lock.Lock()
+	if this.stopped {
+		this.lock.Unlock()
+		logger.Debug("sse-channel-already-stopped", "key", this.Key)
 		return
 	}
+	this.stopped = true
+	close(this.done)
 
-	this.lock.Lock()
-	defer this.lock.Unlock()
-
-	glog.V(100).Infoln("Closing stop", this.Key)
-	close(this.stop)
-	this.stop = nil
-
-	glog.V(100).Infoln("Closing messages", this.Key)
-	close(this.messages)
 	// stop all clients
 	for c, _ := range this.clients {
-		glog.V(100).Infoln("Closing event client", c)
+		logger.Debug("sse-client-closing", "key", this.Key)
+		delete(this.clients, c)
 		close(c)
+		sse_clients.With().Dec()
 	}
+	this.lock.Unlock()
+
 	this.engine.deleteSseChannel(this.Key)
 }
 
 func (this *sseChannel) Start() *sseChannel {
 	go func() {
-		defer glog.Infoln("Channel", this.Key, "Stopped.")
+		defer logger.Info("sse-channel-stopped", "key", this.Key)
 		for {
 			select {
 
@@ -191,49 +215,50 @@ func (this *sseChannel) Start() *sseChannel {
 				this.sources += c
 				if this.sources == 0 {
 					// all sources are gone.
-					glog.V(100).Infoln("All sources gone. Closing channel:", this.Key)
+					logger.Debug("sse-sources-gone", "key", this.Key)
 					this.Stop()
-					this.engine.deleteSseChannel(this.Key)
 					return
 				}
 			case s := <-this.newClients:
 				this.lock.Lock()
-				this.clients[s] = 1
+				if this.stopped {
+					close(s)
+				} else {
+					this.clients[s] = 1
+					sse_clients.With().Inc()
+				}
 				this.lock.Unlock()
-				glog.V(100).Infoln("Added new client:", s)
+				logger.Debug("sse-client-added", "key", this.Key)
 
 			case s := <-this.defunctClients:
 				this.lock.Lock()
-				delete(this.clients, s)
+				if _, has := this.clients[s]; has {
+					delete(this.clients, s)
+					close(s)
+					sse_clients.With().Dec()
+				}
 				this.lock.Unlock()
-				close(s)
-				glog.V(100).Infoln("Removed client:", s)
+				logger.Debug("sse-client-removed", "key", this.Key)
 
-			case _, open := <-this.stop:
-				if open {
-					glog.V(100).Infoln("Received stop.", this.Key)
-					this.Stop()
-				} else {
-					glog.V(100).Infoln("Stopping channel loop.", this.Key)
-					return
-				}
-			default:
-				msg, open := <-this.messages
-				if !open || msg == nil {
-					for s, _ := range this.clients {
-						this.defunctClients <- s
-					}
-					this.stop <- 1
+			case <-this.done:
+				logger.Debug("sse-channel-loop-stopping", "key", this.Key)
+				return
 
-					glog.V(100).Infoln("Channel loop stopped", this.Key)
+			case msg, open := <-this.messages:
+				if !open || msg == nil {
+					this.Stop()
+					logger.Debug("sse-channel-loop-stopped", "key", this.Key)
 					return // stop this
 				} else {
 					// There is a new message to send.  For each
 					// attached client, push the new message
 					// into the client's message channel.
+					this.lock.Lock()
 					for s, _ := range this.clients {
 						s <- msg
+						sse_messages.With().Inc()
 					}
+					this.lock.Unlock()
 				}
 			}
 		}
@@ -247,7 +272,7 @@ func (this *sseChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Make sure that the writer supports flushing.
 	f, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		RenderError(w, r, api.NewError(http.StatusInternalServerError, ErrStreamingNotSupported.Error()))
 		return
 	}
 
@@ -257,22 +282,33 @@ func (this *sseChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Add this client to the map of those that should
 	// receive updates
-	this.newClients <- messageChan
+	select {
+	case this.newClients <- messageChan:
+	case <-this.done:
+		return
+	}
 
 	// Listen to the closing of the http connection via the CloseNotifier
 	notify := w.(http.CloseNotifier).CloseNotify()
 	go func() {
-		<-notify
-		// Remove this client from the map of attached clients
-		// when `EventHandler` exits.
-		this.defunctClients <- messageChan
-		glog.V(100).Infoln("HTTP connection just closed.")
+		select {
+		case <-notify:
+			// Remove this client from the map of attached clients
+			// when `EventHandler` exits.
+			select {
+			case this.defunctClients <- messageChan:
+			case <-this.done:
+			}
+			logger.Debug("sse-connection-closed", "key", this.Key)
+		case <-this.done:
+		}
 	}()
 
 	// Set the headers related to event streaming.
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
+	f.Flush()
 
 	for {
 
@@ -280,9 +316,11 @@ func (this *sseChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		msg, open := <-messageChan
 
 		if !open || msg == nil {
-			// If our messageChan was closed, this means that the client has
-			// disconnected.
-			glog.V(100).Infoln("Messages stopped.. Closing http connection")
+			// If our messageChan was closed, either the client has
+			// disconnected or the channel has stopped.
+			logger.Debug("sse-messages-stopped", "path", r.URL.Path)
+			fmt.Fprintf(w, "event: %s\ndata: \n\n", SseCloseEvent)
+			f.Flush()
 			break
 		}
 
@@ -290,15 +328,15 @@ func (this *sseChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		case "application/json":
 			fmt.Fprintf(w, "event: %s\n", this.EventType)
 			fmt.Fprint(w, "data: ")
-			json_marshaler(this.ContentType, w, &msg, no_header)
+			marshal(this.ContentType, w, &msg, no_header)
 			fmt.Fprint(w, "\n\n")
 		case "text/plain":
 			fmt.Fprintf(w, "%s\n", msg)
 		default:
-			if m, ok := marshalers[this.ContentType]; ok {
+			if _, ok := GetCodec(this.ContentType); ok {
 				fmt.Fprintf(w, "event: %s\n", this.EventType)
 				fmt.Fprint(w, "data: ")
-				m(this.ContentType, w, &msg, no_header)
+				marshal(this.ContentType, w, msg, no_header)
 				fmt.Fprint(w, "\n\n")
 			}
 		}
@@ -309,5 +347,5 @@ func (this *sseChannel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Done.
-	glog.V(100).Infoln("Finished HTTP request at ", r.URL.Path, "num_channels=", len(this.engine.sseChannels))
+	logger.Debug("sse-request-finished", "path", r.URL.Path)
 }
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"bufio"
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDrainEndsEventStreams(t *testing.T) {
+	source := make(chan interface{})
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetHandler(api.MethodSpec{
+		UrlRoute:   "/events",
+		HttpMethod: api.GET,
+	}, func(resp http.ResponseWriter, req *http.Request) {
+		e.MergeHttpStream(resp, req, "text/plain", "message", "events", source)
+	}))
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	assert.Equal(t, nil, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	source <- "hello"
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hello\n", line)
+
+	// a concurrent request is served while the stream is open
+	other, err := http.Get(server.URL + "/api-docs-not-found")
+	assert.Equal(t, nil, err)
+	other.Body.Close()
+
+	e.Drain()
+	rest := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		rest = append(rest, line)
+	}
+	assert.Equal(t, "event: "+SseCloseEvent+"\ndata: \n\n", strings.Join(rest, ""))
+}
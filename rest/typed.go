@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"io"
+	"net/http"
+)
+
+// The inputs of a typed handler, decoded by the engine according to the method spec.
+type Request struct {
+	Body        interface{}
+	UrlQueries  api.UrlQueries
+	FormParams  api.FormParams
+	HttpHeaders map[string][]string
+	HttpRequest *http.Request
+}
+
+// Typed handlers receive the decoded request and return the response object which is
+// encoded with the content type negotiated from the Accept header.  The auth context is
+// nil if the method requires no auth scope.  Errors are rendered with status 500 unless
+// they are an *api.Error, which carries its own status.
+type TypedHandler func(auth.Context, *Request) (interface{}, error)
+
+func SetTypedHandler(serviceId string, m api.MethodSpec, h TypedHandler) *ServiceMethodImpl {
+	return &ServiceMethodImpl{
+		Api:          m,
+		TypedHandler: h,
+		ServiceId:    serviceId,
+	}
+}
+
+func (this *engine) typed_handler(ep *ServiceMethodImpl) auth.HttpHandler {
+	return func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		contentType, err := negotiate_response(req, &ep.Api)
+		if err != nil {
+			this.HandleError(resp, req, err.Error(), http.StatusNotAcceptable)
+			return
+		}
+
+		request, err := this.decode_request(ep.Api, req)
+		if err != nil {
+			this.handle_input_error(resp, req, err)
+			return
+		}
+
+		result, err := ep.TypedHandler(ctx, request)
+		if err != nil {
+			RenderError(resp, req, api.ToError(err, http.StatusInternalServerError))
+			return
+		}
+		if result == nil {
+			resp.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if err := marshal(contentType, resp, result); err != nil {
+			this.HandleError(resp, req, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// Decodes the inputs of the request.  Conversion errors of the inputs are reported along
+// with the violations of the constraints and of the request body's validate tags.
+func (this *engine) decode_request(m api.MethodSpec, req *http.Request) (request *Request, err error) {
+	request = &Request{HttpRequest: req}
+	invalid := &api.ValidationError{}
+	if m.UrlQueries != nil {
+		request.UrlQueries, err = this.GetUrlQueries(req, m.UrlQueries)
+		if err = invalid.Collect(err); err != nil {
+			return nil, err
+		}
+	}
+	if m.FormParams != nil {
+		request.FormParams, err = this.GetPostForm(req, m.FormParams)
+		if err = invalid.Collect(err); err != nil {
+			return nil, err
+		}
+	}
+	if m.HttpHeaders != nil {
+		if request.HttpHeaders, err = this.GetHttpHeaders(req, m.HttpHeaders); err != nil {
+			return nil, err
+		}
+	}
+	if m.RequestBody != nil && has_body(req) {
+		request.Body = m.RequestBody(req)
+		switch err = unmarshal(content_type_for_request(req), req.Body, request.Body); err {
+		case nil:
+		case io.EOF:
+			return nil, ErrMissingInput
+		default:
+			return nil, err
+		}
+	}
+
+	invalid.Add(this.check_constraints(m, req, request.UrlQueries, request.FormParams)...)
+	invalid.Add(api.ValidateStruct(request.Body)...)
+	if err := invalid.OrNil(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func has_body(req *http.Request) bool {
+	switch req.Method {
+	case "POST", "PUT", "PATCH":
+		return true
+	}
+	return false
+}
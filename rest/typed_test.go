@@ -0,0 +1,243 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/bmizerany/assert"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type greeting struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+const (
+	PostGreeting api.ServiceMethod = iota
+	GetGreeting
+	FailGreeting
+)
+
+var greetings = api.ServiceMethods{
+	PostGreeting: api.MethodSpec{
+		UrlRoute:   "/greeting/{id}",
+		HttpMethod: api.POST,
+		UrlQueries: api.UrlQueries{"repeat": 1},
+		HttpHeaders: api.HttpHeaders{
+			"lang": "Accept-Language",
+		},
+		RequestBody: func(req *http.Request) interface{} {
+			return &greeting{}
+		},
+		ResponseBody: func(req *http.Request) interface{} {
+			return &greeting{}
+		},
+	},
+	GetGreeting: api.MethodSpec{
+		UrlRoute:   "/greeting",
+		HttpMethod: api.GET,
+		AuthScope:  "greet",
+	},
+	FailGreeting: api.MethodSpec{
+		UrlRoute:   "/fail",
+		HttpMethod: api.GET,
+	},
+}
+
+func greeting_engine() *engine {
+	e := NewEngine(&greetings, auth.Init(auth.Settings{
+		IsAuthOn: func() bool { return false },
+	}), nil)
+	e.Bind(
+		SetTypedHandler("test", greetings[PostGreeting], func(ctx auth.Context, req *Request) (interface{}, error) {
+			g := req.Body.(*greeting)
+			return &greeting{
+				Name:  g.Name + "/" + req.HttpHeaders["lang"][0] + "/" + req.HttpRequest.URL.Path,
+				Count: g.Count * req.UrlQueries["repeat"].(int),
+			}, nil
+		}),
+		SetTypedHandler("test", greetings[GetGreeting], func(ctx auth.Context, req *Request) (interface{}, error) {
+			if ctx == nil {
+				return nil, errors.New("no-auth-context")
+			}
+			return nil, nil
+		}),
+		SetTypedHandler("test", greetings[FailGreeting], func(ctx auth.Context, req *Request) (interface{}, error) {
+			return nil, errors.New("failed")
+		}),
+	)
+	return e
+}
+
+func TestTypedHandlerDecodesAndEncodes(t *testing.T) {
+	e := greeting_engine()
+
+	body, _ := json.Marshal(&greeting{Name: "hello", Count: 2})
+	req, _ := http.NewRequest("POST", "/greeting/1?repeat=3", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Language", "en")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+
+	result := greeting{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "hello/en//greeting/1", result.Name)
+	assert.Equal(t, 6, result.Count)
+}
+
+func TestTypedHandlerBadInput(t *testing.T) {
+	e := greeting_engine()
+
+	req, _ := http.NewRequest("POST", "/greeting/1?repeat=x", bytes.NewBufferString("{}"))
+	req.Header.Set("Accept-Language", "en")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	req, _ = http.NewRequest("POST", "/greeting/1", bytes.NewBufferString(""))
+	req.Header.Set("Accept-Language", "en")
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestTypedHandlerAuthAndErrors(t *testing.T) {
+	e := greeting_engine()
+
+	req, _ := http.NewRequest("GET", "/greeting", nil)
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/fail", nil)
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+type subscription struct {
+	Plan  string `json:"plan" validate:"required,enum=free|pro"`
+	Seats int    `json:"seats" validate:"min=1"`
+}
+
+func TestTypedHandlerValidation(t *testing.T) {
+	spec := api.MethodSpec{
+		UrlRoute:   "/account/{id}/subscription",
+		HttpMethod: api.POST,
+		UrlQueries: api.UrlQueries{"trial": 0},
+		Constraints: api.Constraints{
+			"id":    "regex=^[0-9]+$",
+			"trial": "max=30",
+		},
+		RequestBody: func(req *http.Request) interface{} {
+			return &subscription{}
+		},
+	}
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetTypedHandler("test", spec, func(ctx auth.Context, req *Request) (interface{}, error) {
+		return req.Body, nil
+	}))
+
+	req, _ := http.NewRequest("POST", "/account/abc/subscription?trial=60", bytes.NewBufferString(`{"plan":"gold"}`))
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	result := api.Error{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, http.StatusBadRequest, result.Status)
+	assert.Equal(t, api.ErrInvalidInput.Error(), result.Code)
+	assert.Equal(t, 4, len(result.Fields))
+
+	req, _ = http.NewRequest("POST", "/account/12/subscription?trial=x", bytes.NewBufferString(`{"plan":"pro","seats":2}`))
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, 1, len(result.Fields))
+	assert.Equal(t, "type", result.Fields[0].Rule)
+
+	req, _ = http.NewRequest("POST", "/account/12/subscription?trial=10", bytes.NewBufferString(`{"plan":"pro","seats":2}`))
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestHandlerConstraints(t *testing.T) {
+	spec := api.MethodSpec{
+		UrlRoute:    "/search",
+		HttpMethod:  api.GET,
+		HttpHeaders: api.HttpHeaders{"client": "X-Client-Id"},
+		Constraints: api.Constraints{
+			"client": "required",
+			"q":      "required,minlen=3",
+		},
+	}
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetHandler(spec, func(resp http.ResponseWriter, req *http.Request) {}))
+
+	req, _ := http.NewRequest("GET", "/search?q=ab", nil)
+	req.Header.Set("Accept", "text/plain")
+	resp := httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "text/plain", resp.Header().Get("Content-Type"))
+	t.Log(resp.Body.String())
+
+	req, _ = http.NewRequest("GET", "/search?q=abc", nil)
+	req.Header.Set("X-Client-Id", "ios")
+	resp = httptest.NewRecorder()
+	e.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestNumericConstraints(t *testing.T) {
+	spec := api.MethodSpec{
+		UrlRoute:    "/item/{id}",
+		HttpMethod:  api.GET,
+		HttpHeaders: api.HttpHeaders{"version": "X-Api-Version"},
+		Constraints: api.Constraints{
+			"id":      "min=1,max=99",
+			"version": "min=2",
+		},
+	}
+	e := NewEngine(&api.ServiceMethods{}, nil, nil)
+	e.Bind(SetHandler(spec, func(resp http.ResponseWriter, req *http.Request) {}))
+
+	get := func(path, version string) int {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("X-Api-Version", version)
+		resp := httptest.NewRecorder()
+		e.ServeHTTP(resp, req)
+		return resp.Code
+	}
+	assert.Equal(t, http.StatusOK, get("/item/5", "2"))
+	assert.Equal(t, http.StatusBadRequest, get("/item/100", "2"))
+	assert.Equal(t, http.StatusBadRequest, get("/item/x", "2"))
+	assert.Equal(t, http.StatusBadRequest, get("/item/5", "1"))
+}
+
+func TestBindChecksValidateTags(t *testing.T) {
+	type bad struct {
+		Name string `validate:"maxlen=ten"`
+	}
+	spec := api.MethodSpec{
+		UrlRoute:    "/bad",
+		HttpMethod:  api.POST,
+		RequestBody: func(*http.Request) interface{} { return &bad{} },
+	}
+	defer func() {
+		assert.NotEqual(t, nil, recover())
+	}()
+	NewEngine(&api.ServiceMethods{}, nil, nil).Bind(SetHandler(spec, func(resp http.ResponseWriter, req *http.Request) {}))
+	t.Fatal("bound")
+}
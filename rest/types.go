@@ -10,10 +10,12 @@ import (
 var (
 	ErrMissingInput                 = errors.New("error-missing-input")
 	ErrUnknownContentType           = errors.New("error-no-content-type")
+	ErrNotAcceptable                = errors.New("error-not-acceptable")
 	ErrUnknownMethod                = errors.New("error-unknown-method")
 	ErrIncompatibleType             = errors.New("error-incompatible-type")
 	ErrNotSupportedUrlParameterType = errors.New("error-not-supported-url-query-param-type")
 	ErrNoHttpHeaderSpec             = errors.New("error-no-http-header-spec")
+	ErrStreamingNotSupported        = errors.New("error-streaming-not-supported")
 )
 
 type Handler func(http.ResponseWriter, *http.Request)
@@ -27,7 +29,14 @@ type EngineEvent struct {
 
 type Engine interface {
 	Bind(...*ServiceMethodImpl)
+	Use(...Middleware)
+	UsePrefix(string, ...Middleware)
+	UseMethod(api.ServiceMethod, ...Middleware)
+	UseAuthenticated(...Middleware)
+	UsePrefixAuthenticated(string, ...Middleware)
+	UseMethodAuthenticated(api.ServiceMethod, ...Middleware)
 	Handle(string, http.Handler)
+	ServeApiDocs(api.OpenApiInfo)
 	ServeHTTP(http.ResponseWriter, *http.Request)
 	GetUrlParameter(*http.Request, string) string
 	GetHttpHeaders(*http.Request, api.HttpHeaders) (map[string][]string, error)
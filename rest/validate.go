@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"net/http"
+)
+
+// Checks the url queries, form params, headers and route variables of the request against
+// the constraints declared in the method spec.  All violations are reported together.
+func (this *engine) validate_inputs(m api.MethodSpec, req *http.Request) error {
+	if len(m.Constraints) == 0 {
+		return nil
+	}
+	invalid := &api.ValidationError{}
+	queries, form := api.UrlQueries{}, api.FormParams{}
+	if m.UrlQueries != nil {
+		q, err := this.GetUrlQueries(req, m.UrlQueries)
+		if err = invalid.Collect(err); err != nil {
+			return err
+		}
+		queries = q
+	}
+	if m.FormParams != nil {
+		f, err := this.GetPostForm(req, m.FormParams)
+		if err = invalid.Collect(err); err != nil {
+			return err
+		}
+		form = f
+	}
+	invalid.Add(this.check_constraints(m, req, queries, form)...)
+	return invalid.OrNil()
+}
+
+func (this *engine) check_constraints(m api.MethodSpec, req *http.Request, queries api.UrlQueries, form api.FormParams) []api.FieldError {
+	result := []api.FieldError{}
+	for key, _ := range m.Constraints {
+		if header, has := m.HttpHeaders[key]; has {
+			value := req.Header.Get(header)
+			result = append(result, m.Constraints.Validate(key, value != "", value)...)
+			continue
+		}
+		_, is_query := m.UrlQueries[key]
+		_, is_form := m.FormParams[key]
+		raw := this.GetUrlParameter(req, key)
+		value := interface{}(raw)
+		if v, has := queries[key]; has && v != nil {
+			value = v
+		} else if v, has := form[key]; has && v != nil {
+			value = v
+		} else if (is_query || is_form) && raw != "" {
+			continue // the conversion error is already reported
+		}
+		result = append(result, m.Constraints.Validate(key, raw != "", value)...)
+	}
+	return result
+}
+
+func (this *engine) validated(ep *ServiceMethodImpl, handler Handler) Handler {
+	if len(ep.Api.Constraints) == 0 {
+		return handler
+	}
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if err := this.validate_inputs(ep.Api, req); err != nil {
+			this.handle_input_error(resp, req, err)
+			return
+		}
+		handler(resp, req)
+	}
+}
+
+func (this *engine) validated_auth(ep *ServiceMethodImpl, handler auth.HttpHandler) auth.HttpHandler {
+	if len(ep.Api.Constraints) == 0 {
+		return handler
+	}
+	return func(ctx auth.Context, resp http.ResponseWriter, req *http.Request) {
+		if err := this.validate_inputs(ep.Api, req); err != nil {
+			this.handle_input_error(resp, req, err)
+			return
+		}
+		handler(ctx, resp, req)
+	}
+}
+
+// Renders the errors of decoding and validating the inputs.  Field errors are reported as
+// bad requests and unknown content types as unsupported.
+func (this *engine) handle_input_error(resp http.ResponseWriter, req *http.Request, err error) {
+	status := http.StatusBadRequest
+	if err == ErrUnknownContentType {
+		status = http.StatusUnsupportedMediaType
+	}
+	RenderError(resp, req, api.ToError(err, status))
+}
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"github.com/golang/glog"
 	"net/http"
 	"net/url"
 	"text/template"
+	"time"
 )
 
 var (
@@ -85,18 +85,19 @@ func (hook *Webhook) Send(message interface{}, templateString string) error {
 	}
 
 	go func() {
-		glog.Infoln("Sending callback to", url)
+		logger.Info("sending-callback", "url", url)
 
 		var buffer bytes.Buffer
 		if templateString != "" {
 			t := template.Must(template.New(templateString).Parse(templateString))
 			err := t.Execute(&buffer, message)
 			if err != nil {
-				glog.Warningln("Cannot build payload for event", message)
+				logger.Warning("cannot-build-payload", "url", url, "event", message, "error", err)
+				webhook_deliveries.With("error").Inc()
 				return
 			}
 		} else {
-			glog.Infoln("no-payload", url)
+			logger.Info("no-payload", "url", url)
 		}
 		// Determine where to send the event.
 		client := &http.Client{}
@@ -105,11 +106,16 @@ func (hook *Webhook) Send(message interface{}, templateString string) error {
 		if hook.AuthToken != "" {
 			post.Header.Add("Authorization", "Bearer "+hook.AuthToken)
 		}
+		start := time.Now()
 		resp, err := client.Do(post)
+		webhook_latency.With().Observe(time.Since(start).Seconds())
 		if err != nil {
-			glog.Warningln("Cannot deliver callback to", url, "error:", err)
+			logger.Warning("cannot-deliver-callback", "url", url, "error", err)
+			webhook_deliveries.With("error").Inc()
 		} else {
-			glog.Infoln("Sent callback to ", url, "response=", resp)
+			logger.Info("sent-callback", "url", url, "status", resp.StatusCode)
+			webhook_deliveries.With(status_class(resp.StatusCode)).Inc()
+			resp.Body.Close()
 		}
 	}()
 	return nil
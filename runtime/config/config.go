@@ -0,0 +1,429 @@
+// Binds configuration structs from layered sources.  In order of precedence, from lowest to
+// highest: the defaults in the struct tags, a JSON, YAML or TOML file, the environment and
+// the command line flags.
+//
+// Each exported field is bound to a key, the `config` tag or the lowercased field name,
+// with the keys of nested structs joined by dots.  The other tags are
+//
+//	default:"value"   the default value
+//	env:"NAME"        the environment variable, EnvPrefix + the upper cased key by default
+//	flag:"name"       the flag, FlagPrefix + the key with underscores by default
+//	required:"true"   the value must not be empty after loading
+//	secret:"true"     the value is redacted when dumped
+//	desc:"text"       the usage of the flag
+//
+// Strings, bools, integers, floats, durations and string slices are supported.  The
+// string slices are comma-separated in the environment and flags.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotStructPointer  = errors.New("config-not-struct-pointer")
+	ErrUnknownFileFormat = errors.New("config-unknown-file-format")
+
+	Redacted = "******"
+)
+
+type Options struct {
+	// The file to load, in the format of its extension: .json, .yaml, .yml or .toml.
+	File string
+	// The top-level key of the file holding this configuration; the whole file if empty.
+	Section string
+
+	EnvPrefix string
+
+	// The flags, flag.CommandLine if nil.  Only the flags set on the command line override
+	// the other sources.
+	Flags      *flag.FlagSet
+	FlagPrefix string
+}
+
+// The keys that are required and missing after loading.
+type MissingKeysError []string
+
+func (this MissingKeysError) Error() string {
+	return "config-missing-required: " + strings.Join(this, ", ")
+}
+
+type field struct {
+	key      string
+	value    reflect.Value
+	tag      reflect.StructTag
+	env      string
+	flag     string
+	required bool
+	secret   bool
+}
+
+var duration_type = reflect.TypeOf(time.Duration(0))
+
+func fields(v interface{}, options Options) ([]*field, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, ErrNotStructPointer
+	}
+	result := []*field{}
+	collect_fields(value.Elem(), "", options, &result)
+	return result, nil
+}
+
+func collect_fields(value reflect.Value, prefix string, options Options, result *[]*field) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := sf.Tag.Get("config")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		key := prefix + name
+		fv := value.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != duration_type {
+			collect_fields(fv, key+".", options, result)
+			continue
+		}
+		if !supported(fv.Type()) {
+			continue
+		}
+		f := &field{
+			key:      key,
+			value:    fv,
+			tag:      sf.Tag,
+			env:      sf.Tag.Get("env"),
+			flag:     sf.Tag.Get("flag"),
+			required: sf.Tag.Get("required") == "true",
+			secret:   sf.Tag.Get("secret") == "true",
+		}
+		if f.env == "" {
+			f.env = options.EnvPrefix + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+		}
+		if f.flag == "" {
+			f.flag = options.FlagPrefix + strings.Replace(key, ".", "_", -1)
+		}
+		*result = append(*result, f)
+	}
+}
+
+func supported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func (this *field) set(s string) error {
+	v := this.value
+	var err error
+	switch {
+	case v.Type() == duration_type:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil {
+			v.SetInt(int64(d))
+		}
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case v.Kind() == reflect.Slice:
+		list := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list).Convert(v.Type()))
+	}
+	if err != nil {
+		return fmt.Errorf("config-bad-value: %s: %v", this.key, err)
+	}
+	return nil
+}
+
+// Sets the value decoded from a file.
+func (this *field) set_decoded(decoded interface{}) error {
+	switch d := decoded.(type) {
+	case []interface{}:
+		items := make([]string, len(d))
+		for i, item := range d {
+			items[i] = format_decoded(item)
+		}
+		return this.set(strings.Join(items, ","))
+	default:
+		return this.set(format_decoded(d))
+	}
+}
+
+func format_decoded(decoded interface{}) string {
+	switch d := decoded.(type) {
+	case float64:
+		return strconv.FormatFloat(d, 'f', -1, 64)
+	case time.Time:
+		return d.Format(time.RFC3339)
+	}
+	return fmt.Sprint(decoded)
+}
+
+// Loads the configuration into the struct pointed to by v.
+func Load(v interface{}, options Options) error {
+	fields, err := fields(v, options)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if d, has := f.tag.Lookup("default"); has {
+			if err := f.set(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	if options.File != "" {
+		values, err := read_file(options.File, options.Section)
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			if decoded, has := values[f.key]; has {
+				if err := f.set_decoded(decoded); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if s, has := os.LookupEnv(f.env); has {
+			if err := f.set(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	flags := options.Flags
+	if flags == nil {
+		flags = flag.CommandLine
+	}
+	set := map[string]*flag.Flag{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = f })
+	for _, f := range fields {
+		if fl, has := set[f.flag]; has {
+			if err := f.set(fl.Value.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	missing := MissingKeysError{}
+	for _, f := range fields {
+		if f.required && is_empty(f.value) {
+			missing = append(missing, f.key)
+		}
+	}
+	if len(missing) > 0 {
+		return missing
+	}
+	return nil
+}
+
+func is_empty(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// Defines the flags of the configuration keys on the flag set, flag.CommandLine if nil.
+// The flags already defined, e.g. by package flag variables, are kept and bound as is.
+// Call before parsing the flags.
+func DefineFlags(v interface{}, options Options) error {
+	fields, err := fields(v, options)
+	if err != nil {
+		return err
+	}
+	flags := options.Flags
+	if flags == nil {
+		flags = flag.CommandLine
+	}
+	for _, f := range fields {
+		if flags.Lookup(f.flag) == nil {
+			value := &flag_value{value: f.tag.Get("default"), bool: f.value.Kind() == reflect.Bool}
+			flags.Var(value, f.flag, f.tag.Get("desc"))
+		}
+	}
+	return nil
+}
+
+// The flags hold strings, parsed when loading; bool flags can be set without a value.
+type flag_value struct {
+	value string
+	bool  bool
+}
+
+func (this *flag_value) String() string {
+	if this == nil {
+		return ""
+	}
+	return this.value
+}
+
+func (this *flag_value) Set(s string) error {
+	this.value = s
+	return nil
+}
+
+func (this *flag_value) IsBoolFlag() bool {
+	return this.bool
+}
+
+// The configuration by key, with the secrets redacted.
+func Dump(v interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	fields, err := fields(v, Options{})
+	if err != nil {
+		return result
+	}
+	for _, f := range fields {
+		switch {
+		case f.secret && !is_empty(f.value):
+			result[f.key] = Redacted
+		case f.value.Type() == duration_type:
+			result[f.key] = time.Duration(f.value.Int()).String()
+		default:
+			result[f.key] = f.value.Interface()
+		}
+	}
+	return result
+}
+
+// The keys of the configuration, sorted.
+func Keys(v interface{}) []string {
+	keys := []string{}
+	for key, _ := range Dump(v) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func read_file(name, section string) (map[string]interface{}, error) {
+	buff, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		err = json.Unmarshal(buff, &decoded)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buff, &decoded)
+	case ".toml":
+		m := map[string]interface{}{}
+		_, err = toml.Decode(string(buff), &m)
+		decoded = m
+	default:
+		return nil, ErrUnknownFileFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	flatten("", normalize(decoded), values)
+	if section == "" {
+		return values, nil
+	}
+	prefix := section + "."
+	result := map[string]interface{}{}
+	for key, value := range values {
+		if strings.HasPrefix(key, prefix) {
+			result[key[len(prefix):]] = value
+		}
+	}
+	return result, nil
+}
+
+// Converts the maps decoded from YAML to string keyed maps.
+func normalize(decoded interface{}) interface{} {
+	switch d := decoded.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, v := range d {
+			m[fmt.Sprint(k)] = normalize(v)
+		}
+		return m
+	case map[string]interface{}:
+		for k, v := range d {
+			d[k] = normalize(v)
+		}
+		return d
+	case []interface{}:
+		for i, v := range d {
+			d[i] = normalize(v)
+		}
+		return d
+	case []map[string]interface{}:
+		list := make([]interface{}, len(d))
+		for i, v := range d {
+			list[i] = normalize(v)
+		}
+		return list
+	}
+	return decoded
+}
+
+func flatten(prefix string, decoded interface{}, values map[string]interface{}) {
+	if m, ok := decoded.(map[string]interface{}); ok {
+		for k, v := range m {
+			flatten(prefix+k+".", v, values)
+		}
+		return
+	}
+	if prefix != "" {
+		values[prefix[:len(prefix)-1]] = decoded
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type database struct {
+	Host     string        `default:"localhost"`
+	Port     int           `default:"5432"`
+	User     string        `required:"true"`
+	Password string        `secret:"true"`
+	Timeout  time.Duration `default:"5s"`
+}
+
+type service struct {
+	Name     string   `config:"name" required:"true"`
+	Debug    bool     `flag:"debug_service"`
+	Ratio    float64  `default:"0.5"`
+	Tags     []string `env:"TEST_SERVICE_TAGS"`
+	Database database `config:"db"`
+	internal string
+}
+
+func write_file(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Equal(t, nil, err)
+	path := filepath.Join(dir, name)
+	assert.Equal(t, nil, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestPrecedence(t *testing.T) {
+	file := write_file(t, "service.yaml", `
+service:
+  name: from-file
+  ratio: 2
+  tags: [a, b]
+  db:
+    host: db.local
+    user: admin
+    password: from-file
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	os.Setenv("SVC_DB_HOST", "db.env")
+	os.Setenv("TEST_SERVICE_TAGS", "x, y")
+	defer os.Unsetenv("SVC_DB_HOST")
+	defer os.Unsetenv("TEST_SERVICE_TAGS")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	options := Options{File: file, Section: "service", EnvPrefix: "SVC_", Flags: flags}
+	v := service{}
+	assert.Equal(t, nil, DefineFlags(&v, options))
+	assert.Equal(t, nil, flags.Parse([]string{"-db_port=6543", "-debug_service"}))
+
+	assert.Equal(t, nil, Load(&v, options))
+	assert.Equal(t, "from-file", v.Name)
+	assert.Equal(t, true, v.Debug)
+	assert.Equal(t, 2.0, v.Ratio)
+	assert.Equal(t, []string{"x", "y"}, v.Tags)
+	assert.Equal(t, "db.env", v.Database.Host)
+	assert.Equal(t, 6543, v.Database.Port)
+	assert.Equal(t, "admin", v.Database.User)
+	assert.Equal(t, 5*time.Second, v.Database.Timeout)
+
+	dump := Dump(&v)
+	assert.Equal(t, Redacted, dump["db.password"])
+	assert.Equal(t, "5s", dump["db.timeout"])
+	assert.Equal(t, []string{"db.host", "db.password", "db.port", "db.timeout", "db.user",
+		"debug", "name", "ratio", "tags"}, Keys(&v))
+}
+
+func TestFileFormats(t *testing.T) {
+	for name, content := range map[string]string{
+		"c.json": `{"name":"svc","db":{"user":"u","port":6000}}`,
+		"c.toml": "name = \"svc\"\n[db]\nuser = \"u\"\nport = 6000\n",
+		"c.yml":  "name: svc\ndb:\n  user: u\n  port: 6000\n",
+	} {
+		file := write_file(t, name, content)
+		v := service{}
+		assert.Equal(t, nil, Load(&v, Options{File: file, Flags: flag.NewFlagSet("test", flag.ContinueOnError)}))
+		assert.Equal(t, "svc", v.Name)
+		assert.Equal(t, 6000, v.Database.Port)
+		os.RemoveAll(filepath.Dir(file))
+	}
+}
+
+func TestValidation(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	v := service{}
+	err := Load(&v, Options{Flags: flags})
+	assert.Equal(t, MissingKeysError{"name", "db.user"}, err)
+
+	os.Setenv("DB_PORT", "not-a-number")
+	defer os.Unsetenv("DB_PORT")
+	err = Load(&v, Options{Flags: flags})
+	assert.NotEqual(t, nil, err)
+
+	assert.Equal(t, ErrNotStructPointer, Load(v, Options{Flags: flags}))
+}
+
+func TestRegistryReload(t *testing.T) {
+	file := write_file(t, "service.json", `{"name":"one","db":{"user":"u","password":"p"}}`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	r := NewRegistry()
+	v := &service{Ratio: 3}
+	options := Options{File: file, Flags: flag.NewFlagSet("test", flag.ContinueOnError)}
+	assert.Equal(t, nil, r.Register("service", v, options))
+	assert.Equal(t, ErrAlreadyRegistered, r.Register("service", v, options))
+
+	reloaded := []*service{}
+	assert.Equal(t, nil, r.Subscribe("service", func(v interface{}) {
+		reloaded = append(reloaded, v.(*service))
+	}))
+	assert.Equal(t, ErrNotRegistered, r.Subscribe("other", func(interface{}) {}))
+
+	ioutil.WriteFile(file, []byte(`{"name":"two","db":{"user":"u"}}`), 0644)
+	assert.Equal(t, nil, r.Reload())
+	assert.Equal(t, 1, len(reloaded))
+	assert.Equal(t, "two", reloaded[0].Name)
+	assert.Equal(t, "one", v.Name)
+	assert.Equal(t, reloaded[0], r.Get("service"))
+
+	// an invalid configuration is not applied
+	ioutil.WriteFile(file, []byte(`{"db":{"user":"u"}}`), 0644)
+	assert.NotEqual(t, nil, r.Reload())
+	assert.Equal(t, 1, len(reloaded))
+
+	req, _ := http.NewRequest("GET", "/config", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	dump := map[string]map[string]interface{}{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &dump))
+	assert.Equal(t, "two", dump["service"]["name"])
+	assert.Equal(t, "", dump["service"]["db.password"])
+}
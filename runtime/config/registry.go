@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrAlreadyRegistered = errors.New("config-already-registered")
+	ErrNotRegistered     = errors.New("config-not-registered")
+)
+
+// Registry of the named configurations of the components, served on the manager endpoint
+// and reloaded on SIGHUP.  A reload loads each configuration into a new struct, which the
+// subscribers receive if it is valid; the structs given to the subscribers are not modified
+// afterwards, so they can be kept and read without locking.
+type Registry struct {
+	lock    sync.RWMutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	base        reflect.Value // the struct as registered, before loading
+	value       interface{}
+	options     Options
+	subscribers []func(interface{})
+}
+
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Loads the configuration into v and registers it by name.  The values of v before loading
+// are the defaults of the fields without a default tag, also on reload.
+func (this *Registry) Register(name string, v interface{}, options Options) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	base := reflect.New(value.Elem().Type()).Elem()
+	base.Set(value.Elem())
+	if err := Load(v, options); err != nil {
+		return err
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if _, has := this.entries[name]; has {
+		return ErrAlreadyRegistered
+	}
+	this.entries[name] = &entry{base: base, value: v, options: options}
+	return nil
+}
+
+// Calls the function with the new configuration, a pointer to a struct of the registered
+// type, after each successful reload.
+func (this *Registry) Subscribe(name string, f func(interface{})) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	e, has := this.entries[name]
+	if !has {
+		return ErrNotRegistered
+	}
+	e.subscribers = append(e.subscribers, f)
+	return nil
+}
+
+// The current configuration, a pointer to a struct of the registered type, or nil.
+func (this *Registry) Get(name string) interface{} {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if e, has := this.entries[name]; has {
+		return e.value
+	}
+	return nil
+}
+
+// Reloads the configurations from their sources and notifies the subscribers.  The
+// configurations that fail to load are kept as is; the first error is returned.
+func (this *Registry) Reload() (err error) {
+	this.lock.RLock()
+	names := []string{}
+	for name, _ := range this.entries {
+		names = append(names, name)
+	}
+	this.lock.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err1 := this.reload(name); err == nil && err1 != nil {
+			err = err1
+		}
+	}
+	return
+}
+
+func (this *Registry) reload(name string) error {
+	this.lock.RLock()
+	e := this.entries[name]
+	value := reflect.New(e.base.Type())
+	value.Elem().Set(e.base)
+	options := e.options
+	this.lock.RUnlock()
+
+	if err := Load(value.Interface(), options); err != nil {
+		return err
+	}
+
+	loaded := value.Interface()
+	this.lock.Lock()
+	e.value = loaded
+	subscribers := append([]func(interface{}){}, e.subscribers...)
+	this.lock.Unlock()
+
+	for _, f := range subscribers {
+		f(loaded)
+	}
+	return nil
+}
+
+// The configurations by name, with the secrets redacted.
+func (this *Registry) Dump() map[string]map[string]interface{} {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	result := map[string]map[string]interface{}{}
+	for name, e := range this.entries {
+		result[name] = Dump(e.value)
+	}
+	return result
+}
+
+// Serves the effective configurations as json, with the secrets redacted.
+func (this *Registry) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	buff, err := json.Marshal(this.Dump())
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Write(buff)
+}
+
+func Register(name string, v interface{}, options Options) error {
+	return Default.Register(name, v, options)
+}
+
+func Subscribe(name string, f func(interface{})) error {
+	return Default.Subscribe(name, f)
+}
+
+func Reload() error {
+	return Default.Reload()
+}
@@ -3,11 +3,15 @@ package runtime
 import (
 	"fmt"
 	"github.com/golang/glog"
+	"github.com/qorio/omni/health"
+	"github.com/qorio/omni/logging"
 	"github.com/qorio/omni/version"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"sync"
+	"time"
 )
 
 func MinimalContainer(port int, endpoint func() http.Handler, shutdown func() error) {
@@ -21,6 +25,10 @@ func StandardContainer(port int, endpoint func() http.Handler, shutdown func() e
 func start_container(port int, endpoint func() http.Handler, shutdown func() error, runManager bool) {
 	buildInfo := version.BuildInfo()
 
+	if err := logging.Init(); err != nil {
+		exitf("Cannot initialize logging: %v", err)
+	}
+
 	var wg sync.WaitGroup
 	shutdownc := make(chan io.Closer, 1)
 	go HandleSignals(shutdownc)
@@ -28,21 +36,25 @@ func start_container(port int, endpoint func() http.Handler, shutdown func() err
 	// *** The API endpoint ***
 	glog.Infoln("Starting api endpoint")
 	apiDone := make(chan bool)
-	RunServer(&http.Server{
+	apiStopped := RunServer(&http.Server{
 		Handler: endpoint(),
 		Addr:    fmt.Sprintf(":%d", port),
 	}, apiDone)
 
 	// Here is a list of shutdown hooks to execute when receiving the OS signal
 	shutdown_tasks := ShutdownSequence{
+		// Fail the readiness check first, and give the load balancers the time to poll it,
+		// so they stop sending traffic before the listener closes
 		ShutdownHook(func() error {
-			if shutdown != nil {
-				return shutdown()
-			}
+			health.ShuttingDown()
+			glog.Infoln("Readiness set to failing, waiting", *ReadinessGracePeriod)
+			time.Sleep(*ReadinessGracePeriod)
 			return nil
 		}),
+		// Wait for the in-flight requests before running the shutdown of the service
 		ShutdownHook(func() error {
 			apiDone <- true
+			<-apiStopped
 			glog.Infoln("Stopped api endpoint")
 			wg.Done()
 			return nil
@@ -53,17 +65,28 @@ func start_container(port int, endpoint func() http.Handler, shutdown func() err
 		// *** The Manager endpoint ***
 		glog.Infoln("Starting manager endpoint")
 		managerDone := make(chan bool)
-		RunServer(&http.Server{
-			Handler: NewManagerEndPoint(Config{
-				BuildInfo: buildInfo,
-			}),
-			Addr: fmt.Sprintf(":%d", port+1),
+		config := Config{
+			BuildInfo:       buildInfo,
+			Auth:            ManagerAuth,
+			InsecureManager: *InsecureManager,
+		}
+		if *UpdatePublicKeyFile != "" {
+			key, err := ioutil.ReadFile(*UpdatePublicKeyFile)
+			if err != nil {
+				panic(err)
+			}
+			config.UpdatePublicKey = key
+		}
+		managerStopped := RunServer(&http.Server{
+			Handler: NewManagerEndPoint(config),
+			Addr:    fmt.Sprintf(":%d", port+1),
 		}, managerDone)
 
 		shutdown_tasks = append(shutdown_tasks,
 			ShutdownHook(func() error {
 				if runManager {
 					managerDone <- true
+					<-managerStopped
 					glog.Infoln("Stopped manager endpoint")
 					wg.Done()
 				}
@@ -71,11 +94,20 @@ func start_container(port int, endpoint func() http.Handler, shutdown func() err
 			}))
 	}
 
+	shutdown_tasks = append(shutdown_tasks,
+		ShutdownHook(func() error {
+			if shutdown != nil {
+				return shutdown()
+			}
+			return nil
+		}))
+
 	// Pid file
 	pid, pidErr := SavePidFile(fmt.Sprintf("%d", port))
 	shutdown_tasks = append(shutdown_tasks,
 		ShutdownHook(func() error {
-			if pidErr == nil {
+			// After a restart the pid file is the restarted process'
+			if pidErr == nil && owns_pid_file(pid) {
 				os.Remove(pid)
 				glog.Infoln("Removed pid file:", pid)
 			}
@@ -83,6 +115,9 @@ func start_container(port int, endpoint func() http.Handler, shutdown func() err
 			return nil
 		}))
 
+	// Report to the parent if restarted
+	NotifyReady()
+
 	shutdownc <- shutdown_tasks
 	wg.Add(len(shutdown_tasks))
 	wg.Wait()
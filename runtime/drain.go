@@ -0,0 +1,40 @@
+package runtime
+
+import (
+	"context"
+	"flag"
+	"github.com/golang/glog"
+	"net/http"
+	"time"
+)
+
+var (
+	DrainTimeout    = flag.Duration("drain_timeout", 30*time.Second, "Max time for in-flight requests to finish on shutdown")
+	ShutdownTimeout = flag.Duration("shutdown_timeout", 60*time.Second, "Max time for the shutdown sequence before exiting uncleanly")
+
+	ReadinessGracePeriod = flag.Duration("readiness_grace_period", 5*time.Second,
+		"Time between failing the readiness check and closing the listener, for the load balancers to notice")
+)
+
+// Implemented by handlers with long-lived responses, e.g. the event streams of the rest
+// engine, to end them when the server drains so that the connections can be closed.
+type Drainer interface {
+	Drain()
+}
+
+// Stops the server from accepting connections and waits for the in-flight requests to
+// finish.  The remaining connections are closed at the deadline.  Returns true if all
+// connections were drained in time.
+func drain(server *http.Server, timeout time.Duration) bool {
+	if drainer, ok := server.Handler.(Drainer); ok {
+		drainer.Drain()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		glog.Warningln("Drain timeout: closing connections at", server.Addr, err)
+		server.Close()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDrainWaitsForInflightRequests(t *testing.T) {
+	started := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			started <- true
+			time.Sleep(200 * time.Millisecond)
+		}
+		resp.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	// an idle keep-alive connection
+	idle, err := http.Get(server.URL + "/fast")
+	assert.Equal(t, nil, err)
+	ioutil.ReadAll(idle.Body)
+	idle.Body.Close()
+
+	body := make(chan string)
+	go func() {
+		resp, err := (&http.Client{Transport: &http.Transport{}}).Get(server.URL + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		buff, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		body <- string(buff)
+	}()
+	<-started
+
+	assert.Equal(t, true, drain(server.Config, 5*time.Second))
+	assert.Equal(t, "done", <-body)
+}
+
+func TestDrainTimeout(t *testing.T) {
+	block := make(chan bool)
+	started := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		started <- true
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	go http.Get(server.URL)
+	<-started
+	start := time.Now()
+	assert.Equal(t, false, drain(server.Config, 100*time.Millisecond))
+	assert.Equal(t, true, time.Since(start) >= 100*time.Millisecond)
+}
+
+func TestRunServerStopsOnce(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "server.sock")
+	defer func() {
+		// Not handed off on restart
+		listeners_lock.Lock()
+		delete(listeners, addr)
+		listener_addrs = listener_addrs[:len(listener_addrs)-1]
+		listeners_lock.Unlock()
+	}()
+	stop := make(chan bool)
+	stopped := RunServer(&http.Server{Addr: addr, Handler: http.NotFoundHandler()}, stop)
+	stop <- true
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not stopped")
+	}
+}
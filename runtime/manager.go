@@ -2,8 +2,16 @@ package runtime
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"github.com/gorilla/mux"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"github.com/qorio/omni/health"
 	omni_http "github.com/qorio/omni/http"
+	"github.com/qorio/omni/logging"
+	"github.com/qorio/omni/metrics"
+	omni_config "github.com/qorio/omni/runtime/config"
 	"net/http"
 	"time"
 )
@@ -29,17 +37,77 @@ type BuildInfoProvider interface {
 	GetBuildNumber() string
 }
 
+// The update, restart, config and logging requests are authenticated with the manager auth
+// scope, and refused if Auth is not set unless the manager is explicitly insecure.  Updates
+// are verified with the PEM RSA public key and refused without one, unless unsigned updates
+// are allowed.  The update target is the running executable by default.
 type Config struct {
 	BuildInfo BuildInfoProvider
+
+	Auth            auth.Service
+	AuthScope       string
+	InsecureManager bool
+
+	UpdatePublicKey      []byte
+	AllowUnsignedUpdates bool
+	UpdateTarget         string
 }
 
+var (
+	DefaultManagerAuthScope = "manager"
+
+	UpdatePublicKeyFile = flag.String("update_public_key", "",
+		"PEM file of the RSA public key verifying executable updates")
+
+	// The authentication of the manager endpoint of the standard container.  Set before
+	// starting the container.
+	ManagerAuth auth.Service
+
+	InsecureManager = flag.Bool("insecure_manager", false,
+		"Serve the manager endpoint without authentication")
+
+	ErrManagerAuthNotConfigured = errors.New("manager-auth-not-configured")
+)
+
 func NewManagerEndPoint(config Config) http.Handler {
 	router := mux.NewRouter()
-	router.HandleFunc("/update", StartUpdateHandler).Methods("POST").Name("update")
+	router.HandleFunc("/update", config.authorized(config.StartUpdateHandler)).
+		Methods("POST").Name("update")
+	router.HandleFunc("/update", config.authorized(UpdateHistoryHandler)).
+		Methods("GET").Name("update-history")
+	router.HandleFunc("/update/{id}", config.authorized(config.UpdateStatusHandler)).
+		Methods("GET").Name("update-status")
+	router.HandleFunc("/restart", config.authorized(RestartHandler)).Methods("POST").Name("restart")
 	router.HandleFunc("/info", config.InfoHandler).Methods("GET").Name("info")
+	router.HandleFunc("/config", config.authorized(omni_config.Default.ServeHTTP)).
+		Methods("GET").Name("config")
+	router.HandleFunc("/logging", config.authorized(logging.LevelsHandler)).
+		Methods("GET", "PUT").Name("logging")
+	router.HandleFunc("/healthz", health.Default.LivenessHandler).Methods("GET").Name("healthz")
+	router.HandleFunc("/readyz", health.Default.ReadinessHandler).Methods("GET").Name("readyz")
+	router.Handle("/metrics", metrics.Default).Methods("GET").Name("metrics")
 	return router
 }
 
+func (config *Config) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	if config.Auth == nil {
+		if config.InsecureManager {
+			return handler
+		}
+		return func(resp http.ResponseWriter, req *http.Request) {
+			api.RenderJSONError(resp, req, ErrManagerAuthNotConfigured.Error(), http.StatusForbidden)
+		}
+	}
+	scope := config.AuthScope
+	if scope == "" {
+		scope = DefaultManagerAuthScope
+	}
+	return config.Auth.RequiresAuth(scope, nil,
+		func(_ auth.Context, resp http.ResponseWriter, req *http.Request) {
+			handler(resp, req)
+		})
+}
+
 func (config *Config) InfoHandler(resp http.ResponseWriter, request *http.Request) {
 	omni_http.SetCORSHeaders(resp)
 	buildInfo := config.BuildInfo
@@ -0,0 +1,222 @@
+package runtime
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/golang/glog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Zero-downtime restart: the running process starts the executable again, handing its
+// listeners to the child as inherited file descriptors.  Once the child reports ready the
+// parent drains its connections and exits, while the child accepts on the same sockets.
+
+const (
+	// The addresses of the inherited listeners, in the order of their file descriptors
+	// starting at 3.
+	EnvListenAddrs = "OMNI_LISTEN_ADDRS"
+
+	// The file descriptor where the child writes when it is ready.
+	EnvReadyFd = "OMNI_READY_FD"
+)
+
+var (
+	ErrRestarting          = errors.New("error-restart-in-progress")
+	ErrRestartNotReady     = errors.New("error-restart-child-not-ready")
+	ErrListenerUnsupported = errors.New("error-listener-not-handed-off")
+
+	RestartReadyTimeout = flag.Duration("restart_ready_timeout", 30*time.Second, "Max time for the restarted process to report ready")
+)
+
+var (
+	listeners      = make(map[string]net.Listener)
+	listener_addrs = []string{}
+	listeners_lock sync.Mutex
+
+	inherited      map[string]net.Listener
+	inherited_once sync.Once
+
+	restarting      bool
+	restarting_lock sync.Mutex
+)
+
+// Listens on the address, using the listener inherited from the parent on restart if any.
+func listen(protocol, addr string) (net.Listener, error) {
+	inherited_once.Do(load_inherited)
+
+	listeners_lock.Lock()
+	defer listeners_lock.Unlock()
+
+	listener, has := inherited[addr]
+	if has {
+		delete(inherited, addr)
+		glog.Infoln("Using inherited listener at", addr)
+	} else {
+		var err error
+		if listener, err = net.Listen(protocol, addr); err != nil {
+			return nil, err
+		}
+	}
+	if _, has := listeners[addr]; !has {
+		listener_addrs = append(listener_addrs, addr)
+	}
+	listeners[addr] = listener
+	return listener, nil
+}
+
+func load_inherited() {
+	inherited = make(map[string]net.Listener)
+	addrs := os.Getenv(EnvListenAddrs)
+	if addrs == "" {
+		return
+	}
+	os.Unsetenv(EnvListenAddrs)
+	for i, addr := range strings.Split(addrs, ",") {
+		f := os.NewFile(uintptr(3+i), addr)
+		listener, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			glog.Warningln("Cannot use inherited listener", addr, err)
+			continue
+		}
+		inherited[addr] = listener
+	}
+}
+
+// Reports to the parent process that the restarted process is serving.  No-op if the
+// process was not started by Restart.
+func NotifyReady() {
+	fd, err := strconv.Atoi(os.Getenv(EnvReadyFd))
+	if err != nil {
+		return
+	}
+	os.Unsetenv(EnvReadyFd)
+	f := os.NewFile(uintptr(fd), "ready")
+	f.Write([]byte{1})
+	f.Close()
+	glog.Infoln("Notified parent", os.Getppid(), "of readiness")
+}
+
+// Restarts the executable, e.g. after an update, handing off the listeners to the new
+// process.  Once the new process is ready, this process shuts down gracefully.
+func Restart() error {
+	restarting_lock.Lock()
+	if restarting {
+		restarting_lock.Unlock()
+		return ErrRestarting
+	}
+	restarting = true
+	restarting_lock.Unlock()
+
+	pid, err := start_child()
+	if err != nil {
+		restarting_lock.Lock()
+		restarting = false
+		restarting_lock.Unlock()
+		return err
+	}
+	glog.Infoln("Restarted as process", pid, ": shutting down")
+	return syscall.Kill(os.Getpid(), syscall.SIGTERM)
+}
+
+func restart() {
+	if err := Restart(); err != nil {
+		glog.Warningln("restart-error", err)
+	}
+}
+
+type file_listener interface {
+	File() (*os.File, error)
+}
+
+func start_child() (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	listeners_lock.Lock()
+	addrs := append([]string{}, listener_addrs...)
+	files := []*os.File{}
+	for _, addr := range addrs {
+		l, ok := listeners[addr].(file_listener)
+		if !ok {
+			listeners_lock.Unlock()
+			close_files(files)
+			return 0, ErrListenerUnsupported
+		}
+		if unix, ok := listeners[addr].(*net.UnixListener); ok {
+			// The socket file is now shared with the child
+			unix.SetUnlinkOnClose(false)
+		}
+		f, err := l.File()
+		if err != nil {
+			listeners_lock.Unlock()
+			close_files(files)
+			return 0, err
+		}
+		files = append(files, f)
+	}
+	listeners_lock.Unlock()
+	defer close_files(files)
+
+	ready_r, ready_w, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	defer ready_r.Close()
+
+	env := []string{}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, EnvListenAddrs+"=") && !strings.HasPrefix(kv, EnvReadyFd+"=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		EnvListenAddrs+"="+strings.Join(addrs, ","),
+		fmt.Sprintf("%s=%d", EnvReadyFd, 3+len(files)))
+
+	fds := []*os.File{os.Stdin, os.Stdout, os.Stderr}
+	fds = append(fds, files...)
+	fds = append(fds, ready_w)
+	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
+		Env:   env,
+		Files: fds,
+	})
+	ready_w.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	ready := make(chan bool, 1)
+	go func() {
+		buff := make([]byte, 1)
+		n, _ := ready_r.Read(buff)
+		ready <- n == 1
+	}()
+	select {
+	case ok := <-ready:
+		if ok {
+			go process.Wait()
+			return process.Pid, nil
+		}
+	case <-time.After(*RestartReadyTimeout):
+	}
+	glog.Warningln("Restarted process", process.Pid, "not ready: killing it")
+	process.Kill()
+	process.Wait()
+	return 0, ErrRestartNotReady
+}
+
+func close_files(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
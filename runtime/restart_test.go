@@ -0,0 +1,53 @@
+package runtime
+
+import (
+	"fmt"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+)
+
+const helper_addr = "127.0.0.1:0"
+
+// Runs as the restarted process in TestRestartHandsOffListeners.
+func TestHelperRestartedProcess(t *testing.T) {
+	if os.Getenv("OMNI_TEST_RESTARTED") != "1" {
+		return
+	}
+	listener, err := listen("tcp", helper_addr)
+	if err != nil {
+		os.Exit(1)
+	}
+	NotifyReady()
+	http.Serve(listener, http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Write([]byte(fmt.Sprintf("%d", os.Getpid())))
+		go os.Exit(0)
+	}))
+}
+
+func TestRestartHandsOffListeners(t *testing.T) {
+	listener, err := listen("tcp", helper_addr)
+	assert.Equal(t, nil, err)
+	addr := listener.Addr().String()
+	defer listener.Close()
+
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=TestHelperRestartedProcess"}
+	os.Setenv("OMNI_TEST_RESTARTED", "1")
+	defer func() {
+		os.Args = args
+		os.Unsetenv("OMNI_TEST_RESTARTED")
+	}()
+
+	// This process does not accept so the connection is served by the child
+	pid, err := start_child()
+	assert.Equal(t, nil, err)
+
+	resp, err := http.Get("http://" + addr)
+	assert.Equal(t, nil, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, fmt.Sprintf("%d", pid), string(body))
+}
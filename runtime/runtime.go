@@ -3,14 +3,16 @@ package runtime
 import (
 	"fmt"
 	"github.com/golang/glog"
+	omni_config "github.com/qorio/omni/runtime/config"
 	"io"
-	"net"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -27,6 +29,11 @@ func SavePidFile(args ...string) (string, error) {
 	return pidFile.Name(), nil
 }
 
+func owns_pid_file(name string) bool {
+	buff, err := ioutil.ReadFile(name)
+	return err == nil && string(buff) == fmt.Sprintf("%d", os.Getpid())
+}
+
 type ShutdownSequence []io.Closer
 
 func ShutdownHook(h func() error) closeWrapper {
@@ -59,10 +66,39 @@ func exitf(pattern string, args ...interface{}) {
 	os.Exit(1)
 }
 
+var (
+	reload_hooks      []func() error
+	reload_hooks_lock sync.Mutex
+)
+
+func init() {
+	OnReload(omni_config.Reload)
+}
+
+// Registers a hook to run on SIGHUP, e.g. to reload the configuration.
+func OnReload(hook func() error) {
+	reload_hooks_lock.Lock()
+	defer reload_hooks_lock.Unlock()
+	reload_hooks = append(reload_hooks, hook)
+}
+
+func reload() {
+	reload_hooks_lock.Lock()
+	hooks := append([]func() error{}, reload_hooks...)
+	reload_hooks_lock.Unlock()
+	for _, hook := range hooks {
+		if err := hook(); err != nil {
+			glog.Warningln("Error reloading:", err)
+		}
+	}
+}
+
+// Handles SIGINT and SIGTERM by running the shutdown sequence received from shutdownc,
+// SIGHUP by running the reload hooks and SIGUSR2 by restarting with the listeners handed
+// off.  The process exits uncleanly if the shutdown takes longer than the shutdown timeout.
 func HandleSignals(shutdownc <-chan io.Closer) {
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGHUP)
-	signal.Notify(c, syscall.SIGINT)
+	signal.Notify(c, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR2)
 	for {
 		sig := <-c
 		sysSig, ok := sig.(syscall.Signal)
@@ -71,8 +107,13 @@ func HandleSignals(shutdownc <-chan io.Closer) {
 		}
 		switch sysSig {
 		case syscall.SIGHUP:
-		case syscall.SIGINT:
-			glog.Warningln("Got SIGTERM: shutting down")
+			glog.Infoln("Got SIGHUP: reloading")
+			reload()
+		case syscall.SIGUSR2:
+			glog.Infoln("Got SIGUSR2: restarting")
+			go restart()
+		case syscall.SIGINT, syscall.SIGTERM:
+			glog.Warningln("Got", sysSig, ": shutting down")
 			donec := make(chan bool)
 			go func() {
 				cl := <-shutdownc
@@ -85,7 +126,7 @@ func HandleSignals(shutdownc <-chan io.Closer) {
 			case <-donec:
 				glog.Infoln("Shut down completed.")
 				os.Exit(0)
-			case <-time.After(5 * time.Second):
+			case <-time.After(*ShutdownTimeout):
 				exitf("Timeout shutting down. Exiting uncleanly.")
 			}
 		default:
@@ -103,7 +144,9 @@ func updateDomainSocketPermissions(filename string) (err error) {
 }
 
 // Runs the http server.  This server offers more control than the standard go's default http server
-// in that when a 'true' is sent to the stop channel, the listener is closed to force a clean shutdown.
+// in that when a 'true' is sent to the stop channel, the listener is closed and the connections are
+// drained: the in-flight requests have up to the drain timeout to finish, and the long-lived responses
+// are ended if the handler is a Drainer.  The stopped channel receives once the server is drained.
 func RunServer(server *http.Server, stop chan bool) (stopped chan bool) {
 	protocol := "tcp"
 	// e.g. 0.0.0.0:80 or :80 or :8080
@@ -111,11 +154,11 @@ func RunServer(server *http.Server, stop chan bool) (stopped chan bool) {
 		protocol = "unix"
 	}
 
-	listener, err := net.Listen(protocol, server.Addr)
+	listener, err := listen(protocol, server.Addr)
 	if err != nil {
 		panic(err)
 	}
-	stopped = make(chan bool)
+	stopped = make(chan bool, 1)
 
 	glog.Infoln("Starting", protocol, "listener at", server.Addr)
 
@@ -123,29 +166,33 @@ func RunServer(server *http.Server, stop chan bool) (stopped chan bool) {
 		updateDomainSocketPermissions(server.Addr)
 	}
 
-	// This will be set to true if a shutdown signal is received. This allows us to detect
-	// if the server stop is intentional or due to some error.
-	fromSignal := false
+	// Closed when the server is stopped intentionally, so that Serve returning is not
+	// taken for an error.  The server is stopped once either way.
+	stopping := make(chan bool)
+	var once sync.Once
+	done := func() { once.Do(func() { stopped <- true }) }
 
 	// The main goroutine where the server listens on the network connection
-	go func(fromSignal *bool) {
+	go func() {
 		// Serve will block until an error (e.g. from shutdown, closed connection) occurs.
 		err := server.Serve(listener)
-		if !*fromSignal {
+		select {
+		case <-stopping:
+		default:
 			glog.Warningln("Warning: server stops due to error", err)
+			done()
 		}
-		stopped <- true
-	}(&fromSignal)
-
-	// Another goroutine that listens for signal to close the network connection
-	// on shutdown.  This will cause the server.Serve() to return.
-	go func(fromSignal *bool) {
-		select {
-		case <-stop:
-			listener.Close()
-			*fromSignal = true // Intentially stopped from signal
-			return
+	}()
+
+	// Another goroutine that listens for signal to shut down the server, which closes the
+	// listener and drains the connections.
+	go func() {
+		<-stop
+		close(stopping)
+		if drain(server, *DrainTimeout) {
+			glog.Infoln("Drained connections at", server.Addr)
 		}
-	}(&fromSignal)
+		done()
+	}()
 	return
 }
@@ -0,0 +1,314 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/inconshreveable/go-update"
+	"github.com/kr/binarydist"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrUpdateInProgress      = errors.New("update-in-progress")
+	ErrUpdateJobNotFound     = errors.New("update-job-not-found")
+	ErrUpdateDownloadStalled = errors.New("update-download-stalled")
+
+	// The download fails when no data is received for the timeout, so a stalled download
+	// does not hold off the later updates while a slow one goes on.
+	UpdateDownloadIdleTimeout = time.Minute
+)
+
+// The phases of an update job.  A job ends installed, failed, rolled back when the install
+// failed with the executable left or restored in place, or restarting.
+const (
+	UpdatePending     = "pending"
+	UpdateDownloading = "downloading"
+	UpdatePatching    = "patching"
+	UpdateVerifying   = "verifying"
+	UpdateApplying    = "applying"
+	UpdateRolledBack  = "rolled_back"
+	UpdateRestarting  = "restarting"
+)
+
+var (
+	update_jobs      = make(map[string]*UpdateJob)
+	update_job_ids   = []string{}
+	update_jobs_lock sync.Mutex
+)
+
+// The versions are the SHA-256 checksums of the executable before and after the update.
+type UpdateJob struct {
+	Id              string    `json:"id"`
+	DownloadUrl     string    `json:"download_url"`
+	Phase           string    `json:"phase"`
+	BytesDownloaded int64     `json:"bytes_downloaded"`
+	BytesTotal      int64     `json:"bytes_total,omitempty"`
+	VersionBefore   string    `json:"version_before,omitempty"`
+	VersionAfter    string    `json:"version_after,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	Restart         bool      `json:"restart,omitempty"`
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished,omitempty"`
+
+	lock sync.Mutex
+}
+
+func new_update_job_id() string {
+	buff := make([]byte, 8)
+	if _, err := rand.Read(buff); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buff)
+}
+
+func new_update_job(request *UpdateExecutableRequest) (*UpdateJob, error) {
+	update_jobs_lock.Lock()
+	defer update_jobs_lock.Unlock()
+
+	for _, id := range update_job_ids {
+		if !update_jobs[id].done() {
+			return nil, ErrUpdateInProgress
+		}
+	}
+	job := &UpdateJob{
+		Id:          new_update_job_id(),
+		DownloadUrl: request.DownloadUrl,
+		Phase:       UpdatePending,
+		Restart:     request.Restart,
+		Started:     time.Now(),
+	}
+	update_jobs[job.Id] = job
+	update_job_ids = append(update_job_ids, job.Id)
+	if len(update_job_ids) > MaxUpdateHistory {
+		delete(update_jobs, update_job_ids[0])
+		update_job_ids = update_job_ids[1:]
+	}
+	return job, nil
+}
+
+// The update job by id, or nil if unknown.
+func GetUpdateJob(id string) *UpdateJob {
+	update_jobs_lock.Lock()
+	defer update_jobs_lock.Unlock()
+	return update_jobs[id]
+}
+
+// A copy of the job for reporting.
+func (this *UpdateJob) Status() *UpdateJob {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return &UpdateJob{
+		Id:              this.Id,
+		DownloadUrl:     this.DownloadUrl,
+		Phase:           this.Phase,
+		BytesDownloaded: this.BytesDownloaded,
+		BytesTotal:      this.BytesTotal,
+		VersionBefore:   this.VersionBefore,
+		VersionAfter:    this.VersionAfter,
+		Error:           this.Error,
+		Restart:         this.Restart,
+		Started:         this.Started,
+		Finished:        this.Finished,
+	}
+}
+
+func (this *UpdateJob) done() bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return !this.Finished.IsZero()
+}
+
+func (this *UpdateJob) set_phase(phase string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.Phase = phase
+}
+
+func (this *UpdateJob) finish(phase string, err error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.Phase = phase
+	if err != nil {
+		this.Error = err.Error()
+	}
+	this.Finished = time.Now()
+}
+
+// Counts the bytes downloaded by the job, and pushes back the idle deadline.
+type download_counter struct {
+	job  *UpdateJob
+	idle *time.Timer
+}
+
+func (this download_counter) Write(buff []byte) (int, error) {
+	this.idle.Reset(UpdateDownloadIdleTimeout)
+	this.job.lock.Lock()
+	defer this.job.lock.Unlock()
+	this.job.BytesDownloaded += int64(len(buff))
+	return len(buff), nil
+}
+
+// Runs the phases of the update: download, patch, verify and apply.  The checksum and the
+// signature are verified before the executable is touched.
+func (this *UpdateJob) run(updater *update.Update) (result UpdateResult) {
+	target := updater.TargetPath
+	if target == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			this.finish(UpdateFailed, err)
+			return UpdateResult{Error: err}
+		}
+		target = executable
+	}
+	if before, err := update.ChecksumForFile(target); err == nil {
+		this.lock.Lock()
+		this.VersionBefore = hex.EncodeToString(before)
+		this.lock.Unlock()
+	}
+
+	this.set_phase(UpdateDownloading)
+	executable, err := this.download()
+	if err != nil {
+		this.finish(UpdateFailed, err)
+		return UpdateResult{Error: err}
+	}
+	defer remove_temp_file(executable)
+
+	if updater.PatchType == update.PATCHTYPE_BSDIFF {
+		this.set_phase(UpdatePatching)
+		patch := executable
+		if executable, err = patch_executable(target, patch); err != nil {
+			this.finish(UpdateFailed, err)
+			return UpdateResult{Error: err}
+		}
+		defer remove_temp_file(executable)
+		updater.ApplyPatch(update.PATCHTYPE_NONE)
+	}
+
+	this.set_phase(UpdateVerifying)
+	if err = verify_executable(updater, executable); err != nil {
+		this.finish(UpdateFailed, err)
+		return UpdateResult{Error: err}
+	}
+
+	this.set_phase(UpdateApplying)
+	result.Error, result.RecoverError = updater.FromStream(executable)
+	switch {
+	case result.RecoverError != nil:
+		this.finish(UpdateFailed, fmt.Errorf("%v; rollback: %v", result.Error, result.RecoverError))
+	case result.Error != nil:
+		this.finish(UpdateRolledBack, result.Error)
+	default:
+		this.lock.Lock()
+		this.VersionAfter = hex.EncodeToString(updater.Checksum)
+		this.lock.Unlock()
+		this.finish(UpdateInstalled, nil)
+	}
+	return
+}
+
+// Downloads to a temporary file, positioned at the start.
+func (this *UpdateJob) download() (*os.File, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stalled int32
+	idle := time.AfterFunc(UpdateDownloadIdleTimeout, func() {
+		atomic.StoreInt32(&stalled, 1)
+		cancel()
+	})
+	defer idle.Stop()
+	failed := func(err error) error {
+		if atomic.LoadInt32(&stalled) == 1 {
+			return ErrUpdateDownloadStalled
+		}
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", this.DownloadUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, failed(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download %s: %s", this.DownloadUrl, resp.Status)
+	}
+	if resp.ContentLength > 0 {
+		this.lock.Lock()
+		this.BytesTotal = resp.ContentLength
+		this.lock.Unlock()
+	}
+	file, err := ioutil.TempFile("", "update")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(file, io.TeeReader(resp.Body, download_counter{this, idle})); err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		remove_temp_file(file)
+		return nil, failed(err)
+	}
+	return file, nil
+}
+
+func remove_temp_file(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// The patched executable in a temporary file, positioned at the start.
+func patch_executable(target string, patch io.Reader) (*os.File, error) {
+	old, err := os.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	defer old.Close()
+	patched, err := ioutil.TempFile("", "update")
+	if err != nil {
+		return nil, err
+	}
+	if err = binarydist.Patch(old, patched, patch); err == nil {
+		_, err = patched.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		remove_temp_file(patched)
+		return nil, err
+	}
+	return patched, nil
+}
+
+// Reads the executable through, and back to the start.
+func verify_executable(updater *update.Update, executable io.ReadSeeker) error {
+	checksum, err := update.ChecksumForReader(executable)
+	if err != nil {
+		return err
+	}
+	if _, err := executable.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum, updater.Checksum) {
+		return fmt.Errorf("%s: expected %x, got %x", ErrUpdateBadChecksum, updater.Checksum, checksum)
+	}
+	if updater.PublicKey != nil {
+		if err := rsa.VerifyPKCS1v15(updater.PublicKey, crypto.SHA256, checksum, updater.Signature); err != nil {
+			return fmt.Errorf("%s: %v", ErrUpdateBadSignature, err)
+		}
+	}
+	return nil
+}
@@ -1,19 +1,53 @@
 package runtime
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/golang/glog"
+	"github.com/gorilla/mux"
 	"github.com/inconshreveable/go-update"
+	"github.com/qorio/omni/api"
 	omni_http "github.com/qorio/omni/http"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
+var (
+	ErrUpdateNoDownloadUrl = errors.New("update-no-download-url")
+	ErrUpdateNoChecksum    = errors.New("update-no-checksum")
+	ErrUpdateBadChecksum   = errors.New("update-bad-checksum")
+	ErrUpdateNoSignature   = errors.New("update-no-signature")
+	ErrUpdateBadSignature  = errors.New("update-bad-signature")
+	ErrUpdateNoPublicKey   = errors.New("update-no-public-key")
+	ErrUpdateBadPublicKey  = errors.New("update-bad-public-key")
+	ErrUpdateBadPatch      = errors.New("update-bad-patch-type")
+
+	// The number of updates kept in the history reported by GET /update.
+	MaxUpdateHistory = 20
+)
+
+const (
+	UpdateInstalled = "installed"
+	UpdateFailed    = "failed"
+)
+
+// The checksum is the hex SHA-256 of the new executable, also when the download is a
+// bsdiff patch of the running executable.  The signature is the base64 RSA PKCS#1 v1.5
+// signature of the checksum.
 type UpdateExecutableRequest struct {
 	DownloadUrl string `json:"downloadUrl"`
+	Checksum    string `json:"checksum"`
+	Signature   string `json:"signature,omitempty"`
+	Patch       string `json:"patch,omitempty"`
+	// Restart with the listeners handed off once installed
+	Restart bool `json:"restart,omitempty"`
 }
 
 type UpdateResult struct {
@@ -21,11 +55,99 @@ type UpdateResult struct {
 	RecoverError error
 }
 
-func StartUpdateHandler(resp http.ResponseWriter, request *http.Request) {
+type UpdateRecord struct {
+	Time        time.Time `json:"time"`
+	DownloadUrl string    `json:"download_url"`
+	Checksum    string    `json:"checksum"`
+	Patch       string    `json:"patch,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+}
+
+var (
+	update_history      = []UpdateRecord{}
+	update_history_lock sync.Mutex
+)
+
+func record_update(request *UpdateExecutableRequest, result UpdateResult) {
+	record := UpdateRecord{
+		Time:        time.Now(),
+		DownloadUrl: request.DownloadUrl,
+		Checksum:    request.Checksum,
+		Patch:       request.Patch,
+		Status:      UpdateInstalled,
+	}
+	if result.Error != nil {
+		record.Status = UpdateFailed
+		record.Error = result.Error.Error()
+	} else if result.RecoverError != nil {
+		record.Status = UpdateFailed
+		record.Error = result.RecoverError.Error()
+	}
+
+	update_history_lock.Lock()
+	defer update_history_lock.Unlock()
+	update_history = append(update_history, record)
+	if len(update_history) > MaxUpdateHistory {
+		update_history = update_history[len(update_history)-MaxUpdateHistory:]
+	}
+}
+
+// The updates since the process started, oldest first.
+func UpdateHistory() []UpdateRecord {
+	update_history_lock.Lock()
+	defer update_history_lock.Unlock()
+	return append([]UpdateRecord{}, update_history...)
+}
+
+// Builds the update verifying the checksum and the signature of the request.
+func (this *UpdateExecutableRequest) updater(publicKey []byte, allowUnsigned bool) (*update.Update, error) {
+	if this.DownloadUrl == "" {
+		return nil, ErrUpdateNoDownloadUrl
+	}
+	if this.Checksum == "" {
+		return nil, ErrUpdateNoChecksum
+	}
+	checksum, err := hex.DecodeString(this.Checksum)
+	if err != nil || len(checksum) != 32 {
+		return nil, ErrUpdateBadChecksum
+	}
+	updater := update.New().VerifyChecksum(checksum)
+
+	switch update.PatchType(this.Patch) {
+	case update.PATCHTYPE_NONE:
+	case update.PATCHTYPE_BSDIFF:
+		updater.ApplyPatch(update.PATCHTYPE_BSDIFF)
+	default:
+		return nil, ErrUpdateBadPatch
+	}
+
+	switch {
+	case publicKey == nil && !allowUnsigned:
+		return nil, ErrUpdateNoPublicKey
+	case publicKey == nil:
+		return updater, nil
+	case this.Signature == "":
+		return nil, ErrUpdateNoSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(this.Signature)
+	if err != nil {
+		return nil, ErrUpdateBadSignature
+	}
+	if _, err := updater.VerifySignature(signature).VerifySignatureWithPEM(publicKey); err != nil {
+		glog.Warningln("update-public-key-error", err)
+		return nil, ErrUpdateBadPublicKey
+	}
+	return updater, nil
+}
+
+// Starts the update job, which downloads, verifies and installs the executable in the
+// background.  The job is polled at /update/{id}.
+func (config *Config) StartUpdateHandler(resp http.ResponseWriter, request *http.Request) {
 	omni_http.SetCORSHeaders(resp)
 	body, err := ioutil.ReadAll(request.Body)
 	if err != nil {
-		renderJsonError(resp, request, err.Error(), http.StatusInternalServerError)
+		api.RenderJSONError(resp, request, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -35,53 +157,88 @@ func StartUpdateHandler(resp http.ResponseWriter, request *http.Request) {
 		if err := dec.Decode(&message); err == io.EOF {
 			break
 		} else if err != nil {
-			renderJsonError(resp, request, err.Error(), http.StatusBadRequest)
+			api.RenderJSONError(resp, request, err.Error(), http.StatusBadRequest)
 			return
 		}
 	}
 
-	if message.DownloadUrl == "" {
-		renderJsonError(resp, request, "update-no-download-url", http.StatusBadRequest)
+	updater, err := message.updater(config.UpdatePublicKey, config.AllowUnsignedUpdates)
+	if err != nil {
+		glog.Warningln("update-rejected", message.DownloadUrl, err)
+		status := http.StatusBadRequest
+		if err == ErrUpdateNoPublicKey || err == ErrUpdateBadPublicKey {
+			status = http.StatusForbidden
+		}
+		api.RenderJSONError(resp, request, err.Error(), status)
 		return
 	}
+	if config.UpdateTarget != "" {
+		updater.Target(config.UpdateTarget)
+	}
 
-	// Blocks while update is happening
-	result := <-RunUpdate(&message)
-
-	if result.Error != nil {
-		renderJsonError(resp, request, "update-error-download", http.StatusInternalServerError)
+	job, err := RunUpdate(&message, updater)
+	if err != nil {
+		api.RenderJSONError(resp, request, err.Error(), http.StatusConflict)
 		return
-	} else if result.RecoverError != nil {
-		renderJsonError(resp, request, "update-recover-error", http.StatusInternalServerError)
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Location", request.URL.Path+"/"+job.Id)
+	resp.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(resp).Encode(job.Status())
+}
+
+// Reports the status of the update job.
+func (config *Config) UpdateStatusHandler(resp http.ResponseWriter, request *http.Request) {
+	omni_http.SetCORSHeaders(resp)
+	job := GetUpdateJob(mux.Vars(request)["id"])
+	if job == nil {
+		api.RenderJSONError(resp, request, ErrUpdateJobNotFound.Error(), http.StatusNotFound)
 		return
 	}
+	resp.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(resp).Encode(job.Status())
+}
 
-	resp.Write([]byte(fmt.Sprintf("{\"status\":\"installed\"}")))
-	return
+// Reports the history of the updates, oldest first.
+func UpdateHistoryHandler(resp http.ResponseWriter, request *http.Request) {
+	omni_http.SetCORSHeaders(resp)
+	resp.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(resp).Encode(UpdateHistory())
 }
 
-func RunUpdate(request *UpdateExecutableRequest) <-chan UpdateResult {
-	resultChan := make(chan UpdateResult)
-	go func() {
-		updater := update.New()
+// Restarts the process with the listeners handed off to the new process.
+func RestartHandler(resp http.ResponseWriter, request *http.Request) {
+	omni_http.SetCORSHeaders(resp)
+	restarting_lock.Lock()
+	busy := restarting
+	restarting_lock.Unlock()
+	if busy {
+		api.RenderJSONError(resp, request, ErrRestarting.Error(), http.StatusConflict)
+		return
+	}
+	resp.Write([]byte(fmt.Sprintf("{\"status\":\"restarting\"}")))
+	go restart()
+}
 
-		glog.Infoln("Starting update executable from", request.DownloadUrl)
+// Starts the update job.  Only one update runs at a time.
+func RunUpdate(request *UpdateExecutableRequest, updater *update.Update) (*UpdateJob, error) {
+	job, err := new_update_job(request)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		glog.Infoln("Starting update executable from", request.DownloadUrl, "job=", job.Id)
 
-		err, recoverErr := updater.FromUrl(request.DownloadUrl)
-		if err != nil {
-			glog.Warningln("update-executable-error", err)
+		result := job.run(updater)
+		if result.Error != nil {
+			glog.Warningln("update-executable-error", result.Error, "job=", job.Id)
 		}
+		record_update(request, result)
 
-		resultChan <- UpdateResult{
-			Error:        err,
-			RecoverError: recoverErr,
+		if result.Error == nil && result.RecoverError == nil && request.Restart {
+			job.set_phase(UpdateRestarting)
+			restart()
 		}
 	}()
-	return resultChan
-}
-
-func renderJsonError(resp http.ResponseWriter, req *http.Request, message string, code int) (err error) {
-	resp.WriteHeader(code)
-	resp.Write([]byte(fmt.Sprintf("{\"error\":\"%s\"}", message)))
-	return
+	return job, nil
 }
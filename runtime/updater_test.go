@@ -0,0 +1,353 @@
+package runtime
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/bmizerany/assert"
+	"github.com/kr/binarydist"
+	"github.com/qorio/omni/api"
+	"github.com/qorio/omni/auth"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type build_info struct{}
+
+func (build_info) GetCommitHash() string     { return "abc" }
+func (build_info) GetBuildTimestamp() string { return "now" }
+func (build_info) GetBuildNumber() string    { return "1" }
+
+// Permits the requests with the manager scope in the x-scope header.
+type header_auth struct {
+	auth.Service
+}
+
+func (header_auth) RequiresAuth(scope string, _ auth.GetScopesFromToken, handler auth.HttpHandler) func(http.ResponseWriter, *http.Request) {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("x-scope") != scope {
+			api.RenderJSONError(resp, req, "not-permitted", http.StatusUnauthorized)
+			return
+		}
+		handler(nil, resp, req)
+	}
+}
+
+type update_fixture struct {
+	key    *rsa.PrivateKey
+	target string
+	files  map[string][]byte
+	block  chan bool
+	server *httptest.Server
+	config Config
+}
+
+func new_update_fixture(t *testing.T) *update_fixture {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Equal(t, nil, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.Equal(t, nil, err)
+
+	dir, err := ioutil.TempDir("", "update")
+	assert.Equal(t, nil, err)
+	target := filepath.Join(dir, "service")
+	assert.Equal(t, nil, ioutil.WriteFile(target, []byte("old executable"), 0755))
+
+	f := &update_fixture{key: key, target: target, files: map[string][]byte{}, block: make(chan bool)}
+	f.server = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/blocked":
+			<-f.block
+		case "/slow":
+			// A byte at a time, each within the idle timeout of the tests
+			for _, b := range f.files[req.URL.Path] {
+				resp.Write([]byte{b})
+				resp.(http.Flusher).Flush()
+				time.Sleep(20 * time.Millisecond)
+			}
+			return
+		}
+		resp.Write(f.files[req.URL.Path])
+	}))
+	f.config = Config{
+		BuildInfo:       build_info{},
+		Auth:            header_auth{},
+		UpdatePublicKey: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		UpdateTarget:    target,
+	}
+	return f
+}
+
+func (this *update_fixture) close() {
+	this.server.Close()
+	os.RemoveAll(filepath.Dir(this.target))
+}
+
+func (this *update_fixture) sign(executable []byte) (string, string) {
+	checksum := sha256.Sum256(executable)
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, this.key, crypto.SHA256, checksum[:])
+	return hex.EncodeToString(checksum[:]), base64.StdEncoding.EncodeToString(signature)
+}
+
+func (this *update_fixture) post(request UpdateExecutableRequest, scope string) *httptest.ResponseRecorder {
+	buff, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/update", bytes.NewBuffer(buff))
+	req.Header.Set("x-scope", scope)
+	resp := httptest.NewRecorder()
+	NewManagerEndPoint(this.config).ServeHTTP(resp, req)
+	return resp
+}
+
+// Polls the job of the update response until it is done.
+func (this *update_fixture) wait(t *testing.T, resp *httptest.ResponseRecorder) *UpdateJob {
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	job := UpdateJob{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &job))
+	assert.Equal(t, "/update/"+job.Id, resp.Header().Get("Location"))
+	for job.Finished.IsZero() {
+		time.Sleep(10 * time.Millisecond)
+		req, _ := http.NewRequest("GET", "/update/"+job.Id, nil)
+		req.Header.Set("x-scope", DefaultManagerAuthScope)
+		resp := httptest.NewRecorder()
+		NewManagerEndPoint(this.config).ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		job = UpdateJob{}
+		assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &job))
+	}
+	return &job
+}
+
+func error_code(resp *httptest.ResponseRecorder) string {
+	e := api.Error{}
+	json.Unmarshal(resp.Body.Bytes(), &e)
+	return e.Code
+}
+
+func TestSignedUpdate(t *testing.T) {
+	f := new_update_fixture(t)
+	defer f.close()
+
+	executable := []byte("new executable")
+	f.files["/service"] = executable
+	checksum, signature := f.sign(executable)
+	request := UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+		Signature:   signature,
+	}
+
+	resp := f.post(request, "other")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	before, _ := f.sign([]byte("old executable"))
+	job := f.wait(t, f.post(request, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateInstalled, job.Phase)
+	assert.Equal(t, "", job.Error)
+	assert.Equal(t, int64(len(executable)), job.BytesDownloaded)
+	assert.Equal(t, before, job.VersionBefore)
+	assert.Equal(t, checksum, job.VersionAfter)
+	installed, _ := ioutil.ReadFile(f.target)
+	assert.Equal(t, executable, installed)
+
+	// the update is in the history, which is not public
+	req, _ := http.NewRequest("GET", "/update", nil)
+	resp = httptest.NewRecorder()
+	NewManagerEndPoint(f.config).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	req.Header.Set("x-scope", DefaultManagerAuthScope)
+	resp = httptest.NewRecorder()
+	NewManagerEndPoint(f.config).ServeHTTP(resp, req)
+	history := []UpdateRecord{}
+	assert.Equal(t, nil, json.Unmarshal(resp.Body.Bytes(), &history))
+	last := history[len(history)-1]
+	assert.Equal(t, UpdateInstalled, last.Status)
+	assert.Equal(t, checksum, last.Checksum)
+}
+
+func TestRejectedUpdates(t *testing.T) {
+	f := new_update_fixture(t)
+	defer f.close()
+
+	executable := []byte("tampered executable")
+	f.files["/service"] = executable
+	checksum, signature := f.sign([]byte("new executable"))
+
+	resp := f.post(UpdateExecutableRequest{DownloadUrl: f.server.URL + "/service"}, DefaultManagerAuthScope)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, ErrUpdateNoChecksum.Error(), error_code(resp))
+
+	// the checksum does not match the download
+	job := f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+		Signature:   signature,
+	}, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateFailed, job.Phase)
+	assert.Equal(t, true, strings.HasPrefix(job.Error, ErrUpdateBadChecksum.Error()))
+	assert.Equal(t, UpdateFailed, UpdateHistory()[len(UpdateHistory())-1].Status)
+
+	// the signature is not of the download
+	checksum, _ = f.sign(executable)
+	job = f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+		Signature:   signature,
+	}, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateFailed, job.Phase)
+	assert.Equal(t, true, strings.HasPrefix(job.Error, ErrUpdateBadSignature.Error()))
+
+	resp = f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+	}, DefaultManagerAuthScope)
+	assert.Equal(t, ErrUpdateNoSignature.Error(), error_code(resp))
+
+	installed, _ := ioutil.ReadFile(f.target)
+	assert.Equal(t, "old executable", string(installed))
+
+	// no public key configured
+	f.config.UpdatePublicKey = nil
+	resp = f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+	}, DefaultManagerAuthScope)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, ErrUpdateNoPublicKey.Error(), error_code(resp))
+
+	f.config.AllowUnsignedUpdates = true
+	job = f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+	}, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateInstalled, job.Phase)
+}
+
+func TestOneUpdateAtATime(t *testing.T) {
+	f := new_update_fixture(t)
+	defer f.close()
+
+	executable := []byte("new executable")
+	f.files["/blocked"] = executable
+	checksum, signature := f.sign(executable)
+	request := UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/blocked",
+		Checksum:    checksum,
+		Signature:   signature,
+	}
+	first := f.post(request, DefaultManagerAuthScope)
+	resp := f.post(request, DefaultManagerAuthScope)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, ErrUpdateInProgress.Error(), error_code(resp))
+
+	f.block <- true
+	assert.Equal(t, UpdateInstalled, f.wait(t, first).Phase)
+
+	req, _ := http.NewRequest("GET", "/update/unknown", nil)
+	req.Header.Set("x-scope", DefaultManagerAuthScope)
+	resp = httptest.NewRecorder()
+	NewManagerEndPoint(f.config).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestPatchUpdate(t *testing.T) {
+	if _, err := exec.LookPath("bzip2"); err != nil {
+		t.Skip("bzip2 is needed to create patches")
+	}
+	f := new_update_fixture(t)
+	defer f.close()
+
+	executable := []byte("new executable, patched")
+	var patch bytes.Buffer
+	assert.Equal(t, nil, binarydist.Diff(bytes.NewBufferString("old executable"), bytes.NewBuffer(executable), &patch))
+	f.files["/service.patch"] = patch.Bytes()
+	checksum, signature := f.sign(executable)
+
+	job := f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service.patch",
+		Checksum:    checksum,
+		Signature:   signature,
+		Patch:       "bsdiff",
+	}, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateInstalled, job.Phase)
+	installed, _ := ioutil.ReadFile(f.target)
+	assert.Equal(t, executable, installed)
+
+	resp := f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service.patch",
+		Checksum:    checksum,
+		Signature:   signature,
+		Patch:       "xdelta",
+	}, DefaultManagerAuthScope)
+	assert.Equal(t, ErrUpdateBadPatch.Error(), error_code(resp))
+}
+
+func TestManagerWithoutAuth(t *testing.T) {
+	config := Config{BuildInfo: build_info{}}
+	serve := func(method, path string) int {
+		req, _ := http.NewRequest(method, path, nil)
+		resp := httptest.NewRecorder()
+		NewManagerEndPoint(config).ServeHTTP(resp, req)
+		return resp.Code
+	}
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/restart"))
+	assert.Equal(t, http.StatusForbidden, serve("PUT", "/logging"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/config"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/update"))
+	assert.Equal(t, http.StatusOK, serve("GET", "/info"))
+
+	config.InsecureManager = true
+	assert.Equal(t, http.StatusOK, serve("GET", "/config"))
+}
+
+func TestUpdateDownloadTimeout(t *testing.T) {
+	f := new_update_fixture(t)
+	defer f.close()
+	timeout := UpdateDownloadIdleTimeout
+	UpdateDownloadIdleTimeout = 100 * time.Millisecond
+	defer func() { UpdateDownloadIdleTimeout = timeout }()
+
+	executable := []byte("new executable")
+	f.files["/blocked"] = executable
+	f.files["/service"] = executable
+	checksum, signature := f.sign(executable)
+	job := f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/blocked",
+		Checksum:    checksum,
+		Signature:   signature,
+	}, DefaultManagerAuthScope))
+	f.block <- true
+	assert.Equal(t, UpdateFailed, job.Phase)
+	assert.Equal(t, ErrUpdateDownloadStalled.Error(), job.Error)
+
+	// The next update is not held off
+	job = f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/service",
+		Checksum:    checksum,
+		Signature:   signature,
+	}, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateInstalled, job.Phase)
+
+	// A slow download goes on past the idle timeout as long as data comes in
+	f.files["/slow"] = executable
+	job = f.wait(t, f.post(UpdateExecutableRequest{
+		DownloadUrl: f.server.URL + "/slow",
+		Checksum:    checksum,
+		Signature:   signature,
+	}, DefaultManagerAuthScope))
+	assert.Equal(t, UpdateInstalled, job.Phase)
+	assert.Equal(t, true, job.Finished.Sub(job.Started) > UpdateDownloadIdleTimeout)
+}
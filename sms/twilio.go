@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/logging"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -53,6 +53,8 @@ const (
 
 var (
 	TwilioEndpointTemplate *template.Template
+
+	logger = logging.For("sms")
 )
 
 func init() {
@@ -158,7 +160,7 @@ func (t *Twilio) Send(message *Message) (*Response, error) {
 	content, err := ioutil.ReadAll(resp.Body)
 	resp.Body.Close()
 
-	glog.V(200).Infoln("SMS: Response=", string(content))
+	logger.Debug("twilio-response", "status", resp.StatusCode, "response", string(content))
 	r := new(Response)
 	err = json.Unmarshal(content, r)
 	return r, err
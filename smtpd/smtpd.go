@@ -11,7 +11,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"log"
+	"github.com/qorio/omni/health"
+	"github.com/qorio/omni/logging"
 	"net"
 	"os/exec"
 	"regexp"
@@ -24,6 +25,8 @@ var (
 	rcptToRE = regexp.MustCompile(`[Tt][Oo]:<(.+)>`)
 	//mailFromRE = regexp.MustCompile(`(?i)^from:\s*<(.*?)>`)
 	mailFromRE = regexp.MustCompile(`[Ff][Rr][Oo][Mm]:<(.*)>`)
+
+	logger = logging.For("smtpd")
 )
 
 // Server is an SMTP server.
@@ -80,7 +83,7 @@ func (e *BasicEnvelope) BeginData() error {
 }
 
 func (e *BasicEnvelope) Write(line []byte) error {
-	log.Printf("Line: %q", string(line))
+	logger.Debug("line", "line", string(line))
 	return nil
 }
 
@@ -114,15 +117,20 @@ func (srv *Server) ListenAndServe() error {
 	return srv.Serve(ln)
 }
 
+// Serve accepts connections on ln until it fails.  The listener is registered
+// as the health check "smtpd:<addr>", which fails once Serve has returned.
 func (srv *Server) Serve(ln net.Listener) error {
 	defer ln.Close()
+	check := "smtpd:" + ln.Addr().String()
+	health.Register(check, func() error { return nil }, health.Options{})
 	for {
 		rw, e := ln.Accept()
 		if e != nil {
 			if ne, ok := e.(net.Error); ok && ne.Temporary() {
-				log.Printf("smtpd: Accept error: %v", e)
+				logger.Warning("accept-error", "addr", ln.Addr().String(), "error", e)
 				continue
 			}
+			health.Register(check, func() error { return e }, health.Options{})
 			return e
 		}
 		sess, err := srv.newSession(rw)
@@ -156,8 +164,13 @@ func (srv *Server) newSession(rwc net.Conn) (s *session, err error) {
 	return
 }
 
+// The logger with the remote address of the client.
+func (s *session) logger() *logging.Logger {
+	return logger.With("remote_addr", s.rwc.RemoteAddr().String())
+}
+
 func (s *session) errorf(format string, args ...interface{}) {
-	log.Printf("Client error: "+format, args...)
+	s.logger().Warning("client-error", "error", fmt.Sprintf(format, args...))
 }
 
 func (s *session) sendf(format string, args ...interface{}) {
@@ -223,7 +236,7 @@ func (s *session) serve() {
 			arg := line.Arg() // "From:<foo@bar.com>"
 			m := mailFromRE.FindStringSubmatch(arg)
 			if m == nil {
-				log.Printf("invalid MAIL arg: %q", arg)
+				s.logger().Info("invalid-mail-arg", "arg", arg)
 				s.sendlinef("501 5.1.7 Bad sender address syntax")
 				continue
 			}
@@ -233,7 +246,7 @@ func (s *session) serve() {
 		case "DATA":
 			s.handleData()
 		default:
-			log.Printf("Client: %q, verhb: %q", line, line.Verb())
+			s.logger().Info("unknown-command", "line", string(line), "verb", line.Verb())
 			s.sendlinef("502 5.5.2 Error: command not recognized")
 		}
 	}
@@ -268,17 +281,17 @@ func (s *session) handleMailFrom(email string) {
 		s.sendlinef("503 5.5.1 Error: nested MAIL command")
 		return
 	}
-	log.Printf("mail from: %q", email)
+	s.logger().Info("mail-from", "email", email)
 	cb := s.srv.OnNewMail
 	if cb == nil {
-		log.Printf("smtp: Server.OnNewMail is nil; rejecting MAIL FROM")
+		s.logger().Warning("no-on-new-mail", "email", email)
 		s.sendf("451 Server.OnNewMail not configured\r\n")
 		return
 	}
 	s.env = nil
 	env, err := cb(s, addrString(email))
 	if err != nil {
-		log.Printf("rejecting MAIL FROM %q: %v", email, err)
+		s.logger().Info("mail-from-rejected", "email", email, "error", err)
 		s.sendf("451 denied\r\n")
 
 		s.bw.Flush()
@@ -303,7 +316,7 @@ func (s *session) handleRcpt(line cmdLine) {
 	arg := line.Arg() // "To:<foo@bar.com>"
 	m := rcptToRE.FindStringSubmatch(arg)
 	if m == nil {
-		log.Printf("bad RCPT address: %q", arg)
+		s.logger().Info("bad-rcpt-address", "arg", arg)
 		s.sendlinef("501 5.1.7 Bad sender address syntax")
 		return
 	}
@@ -353,7 +366,7 @@ func (s *session) handleError(err error) {
 		s.sendlinef("%s", se)
 		return
 	}
-	log.Printf("Error: %s", err)
+	s.logger().Warning("data-error", "error", err)
 	s.env = nil
 }
 
@@ -0,0 +1,42 @@
+package sql
+
+import (
+	"github.com/qorio/omni/runtime/config"
+	"sync"
+)
+
+// The settings of the package, registered as "sql" with the runtime config on first use,
+// once the flags are parsed.  They are set with the environment, e.g. SQL_MAX_OPEN_CONN,
+// or the flags, e.g. -sql_max_open_conn, and reloaded on SIGHUP.  The user and db are the
+// defaults of NewPostgres.
+type Config struct {
+	MaxOpenConns int    `config:"max_open_conn" desc:"Max number of open connections, 0 is no limit"`
+	MaxIdleConns int    `config:"max_idle_conn" default:"10" desc:"Max number of idle connections"`
+	User         string `desc:"The user of the postgres connections"`
+	Db           string `desc:"The database of the postgres connections"`
+}
+
+var (
+	ConfigOptions = config.Options{EnvPrefix: "SQL_", FlagPrefix: "sql_"}
+
+	register_config sync.Once
+)
+
+func init() {
+	if err := config.DefineFlags(&Config{}, ConfigOptions); err != nil {
+		panic(err)
+	}
+}
+
+// The current settings, the defaults if they could not be registered.
+func CurrentConfig() *Config {
+	register_config.Do(func() {
+		if err := config.Register("sql", &Config{}, ConfigOptions); err != nil {
+			logger.Warning("config-not-registered", "error", err)
+		}
+	})
+	if current, ok := config.Default.Get("sql").(*Config); ok {
+		return current
+	}
+	return &Config{MaxIdleConns: 10}
+}
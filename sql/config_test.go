@@ -0,0 +1,32 @@
+package sql
+
+import (
+	"github.com/qorio/omni/runtime/config"
+	. "gopkg.in/check.v1"
+	"os"
+)
+
+type SqlConfigTests struct{}
+
+var _ = Suite(&SqlConfigTests{})
+
+func (suite *SqlConfigTests) TestConfig(c *C) {
+	c.Assert(CurrentConfig().MaxIdleConns, Equals, 10)
+	c.Assert(config.Default.Get("sql"), Equals, CurrentConfig())
+
+	user, has_user := os.LookupEnv("SQL_USER")
+	defer func() {
+		os.Unsetenv("SQL_MAX_OPEN_CONN")
+		if has_user {
+			os.Setenv("SQL_USER", user)
+		} else {
+			os.Unsetenv("SQL_USER")
+		}
+		config.Reload()
+	}()
+	os.Setenv("SQL_MAX_OPEN_CONN", "5")
+	os.Setenv("SQL_USER", "tester")
+	c.Assert(config.Reload(), IsNil)
+	c.Assert(CurrentConfig().MaxOpenConns, Equals, 5)
+	c.Assert(NewPostgres().User, Equals, "tester")
+}
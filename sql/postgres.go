@@ -3,37 +3,36 @@ package sql
 import (
 	"database/sql"
 	"errors"
-	"flag"
 	"fmt"
-	"github.com/golang/glog"
 	_ "github.com/lib/pq"
+	"github.com/qorio/omni/health"
 	"sync"
 )
 
-var (
-	maxOpenConns = flag.Int("sql_max_open_conn", 0, "Max number of open connections, 0 is no limit")
-	maxIdleConns = flag.Int("sql_max_idle_conn", 10, "Max number of open connections")
-)
-
+// The user and db are those of the sql config.
 func NewPostgres() *Postgres {
+	current := CurrentConfig()
 	return &Postgres{
-		User:            "ubuntu",
-		Db:              "circle_test",
+		User:            current.User,
+		Db:              current.Db,
 		Ssl:             false,
 		DoCreateSchemas: true,
 		DoUpdateSchemas: false,
 	}
 }
 
+// The connection settings can be loaded with the runtime config package, e.g.
+// config.Register("postgres", NewPostgres(), config.Options{EnvPrefix: "POSTGRES_"}),
+// where the values of NewPostgres are the defaults.
 type Postgres struct {
 	Host            string
 	Port            int
-	Db              string
-	User            string
-	Password        string
-	Schemas         []*Schema
-	DoCreateSchemas bool
-	DoUpdateSchemas bool
+	Db              string    `required:"true"`
+	User            string    `required:"true"`
+	Password        string    `secret:"true"`
+	Schemas         []*Schema `config:"-"`
+	DoCreateSchemas bool      `config:"create_schemas"`
+	DoUpdateSchemas bool      `config:"update_schemas"`
 
 	Ssl         bool
 	conn        *sql.DB
@@ -59,8 +58,6 @@ func (this *Postgres) Open() error {
 	conn_string := this.connection_string()
 	this.conn_string = conn_string
 
-	glog.Infoln("Postgres connection string:", conn_string)
-
 	mutex1.Lock()
 	once, has := sync_by_connection_string[conn_string]
 	if !has {
@@ -70,15 +67,16 @@ func (this *Postgres) Open() error {
 	mutex1.Unlock()
 
 	once.Do(func() {
-		glog.Infoln("Connecting to db:", conn_string)
+		logger.Info("connecting", this.log_fields()...)
 		db, err := sql.Open(string(POSTGRES), conn_string)
 		if err != nil {
 			panic(err)
 		}
-		db.SetMaxIdleConns(*maxIdleConns)
-		db.SetMaxOpenConns(*maxOpenConns)
+		current := CurrentConfig()
+		db.SetMaxIdleConns(current.MaxIdleConns)
+		db.SetMaxOpenConns(current.MaxOpenConns)
 		conn_by_connection_string[conn_string] = db
-		glog.Infoln("Connected to db:", conn_string, "caching the connection handle")
+		logger.Info("connected", this.log_fields()...)
 	})
 
 	this.conn, has = conn_by_connection_string[conn_string]
@@ -89,16 +87,18 @@ func (this *Postgres) Open() error {
 	if this.conn == nil {
 		panic(errors.New("error-db-connection-is-nil"))
 	}
-	glog.Infoln("Connected (PING):", this.conn.Ping())
+	logger.Info("ping", append(this.log_fields(), "error", this.conn.Ping())...)
+	health.Register(this.health_check_name(), this.conn.Ping, health.Options{})
+
 	initialize_system.Do(func() {
 		// bootstrap the system schema
 		err1 := postgres_schema.Initialize(this.conn)
-		glog.Infoln("Initialized system schema:", err1)
+		logger.Info("initialized-system-schema", "error", err1)
 		if err1 != nil {
 			panic(err1)
 		}
 		err2 := postgres_schema.PrepareStatements(this.conn)
-		glog.Infoln("Prepared statements:", err2)
+		logger.Info("prepared-statements", "error", err2)
 		if err2 != nil {
 			panic(err2)
 		}
@@ -146,17 +146,28 @@ func (this *Postgres) ReallyClose() error {
 	if this.conn == nil {
 		return ErrNotConnected
 	}
+	health.Unregister(this.health_check_name())
 	err := this.conn.Close()
 	// Remove the entry in the global maps by connection string.
 	// This way, we will connect again when Open is called.
 	mutex1.Lock()
-	glog.Infoln("Removing sync / db handles for", this.conn_string)
+	logger.Info("closing", this.log_fields()...)
 	delete(sync_by_connection_string, this.conn_string)
 	delete(conn_by_connection_string, this.conn_string)
 	mutex1.Unlock()
 	return err
 }
 
+// The fields identifying the database in the logs, without the credentials of the
+// connection string.
+func (this *Postgres) log_fields() []interface{} {
+	return []interface{}{"host", this.Host, "port", this.Port, "db", this.Db, "user", this.User}
+}
+
+func (this *Postgres) health_check_name() string {
+	return fmt.Sprintf("postgres:%s:%d/%s", this.Host, this.Port, this.Db)
+}
+
 func (this *Postgres) DropAll() error {
 	// This just drops everything... dangerous!
 	for _, s := range this.Schemas {
@@ -171,7 +182,7 @@ func (this *Postgres) DropAll() error {
 func (this *Postgres) TruncateAll() error {
 	for _, s := range this.Schemas {
 		for t, _ := range s.CreateTables {
-			glog.Infoln("Truncating", t)
+			logger.Info("truncating", "table", t)
 			_, err := this.conn.Exec("delete from " + t + " where true")
 			if err != nil {
 				return err
@@ -16,6 +16,7 @@ var _ = Suite(&SqlPostgresTests{})
 // Database set up for circle_ci:
 // psql> create role ubuntu login password 'password';
 // psql> create database circle_ci with owner ubuntu encoding 'UTF8';
+// with SQL_USER=ubuntu and SQL_DB=circle_test in the environment.
 func (suite *SqlPostgresTests) SetUpSuite(c *C) {
 	var err error
 	suite.pg = NewPostgres()
@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/metrics"
+	"strconv"
+	"time"
 )
 
 type Schema struct {
@@ -29,6 +31,11 @@ type Statement struct {
 }
 
 var (
+	statement_latency = metrics.NewHistogram("sql_statement_duration_seconds",
+		"Latency of the prepared statements by schema and statement key.", nil, "schema", "statement")
+	statement_errors = metrics.NewCounter("sql_statement_errors_total",
+		"Errors of the prepared statements by schema and statement key.", "schema", "statement")
+
 	ErrNoSystemSchema = errors.New("no-system-schema")
 	ErrOptIsNull      = errors.New("options-is-null")
 	ErrNoCollect      = errors.New("no-collect")
@@ -55,7 +62,7 @@ func (this *Schema) CurrentVersion(db *sql.DB) (int, string, error) {
 	case err != nil:
 		return version, hash, err
 	}
-	glog.Infoln("Checking", this.Name, this.Version, "but finds in db:", version, hash)
+	logger.Info("schema-version", "schema", this.Name, "version", this.Version, "db_version", version, "db_hash", hash)
 	return version, hash, err
 }
 
@@ -70,16 +77,16 @@ func (this *Schema) Initialize(db *sql.DB) (err error) {
 	}
 
 	for _, stmt := range this.CreateTables {
-		glog.V(40).Infoln(stmt)
+		logger.Debug("create-table", "statement", stmt)
 		if _, err := db.Exec(stmt); err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 	for _, stmt := range this.CreateIndexes {
-		glog.V(40).Infoln(stmt)
+		logger.Debug("create-index", "statement", stmt)
 		if _, err := db.Exec(stmt); err != nil {
-			glog.Warningln(stmt, "err:", err)
+			logger.Warning("create-index-error", "statement", stmt, "error", err)
 		}
 	}
 
@@ -109,7 +116,7 @@ func (this *Schema) Update(db *sql.DB) (err error) {
 	}
 	for _, stmt := range this.UpdateIndexes {
 		if _, err := db.Exec(stmt); err != nil {
-			glog.Warningln(stmt, "err:", err)
+			logger.Warning("update-index-error", "statement", stmt, "error", err)
 		}
 	}
 
@@ -148,7 +155,10 @@ func (this *Schema) Exec(db *sql.DB, key StatementKey, params ...interface{}) (s
 			return nil, err
 		}
 	}
-	return stmt.Exec(args...)
+	defer this.observe(key, time.Now())
+	result, err := stmt.Exec(args...)
+	this.count_error(key, err)
+	return result, err
 }
 
 func (this *Schema) Query(db *sql.DB, key StatementKey, params ...interface{}) (*sql.Rows, error) {
@@ -163,7 +173,10 @@ func (this *Schema) Query(db *sql.DB, key StatementKey, params ...interface{}) (
 			return nil, err
 		}
 	}
-	return stmt.Query(args...)
+	defer this.observe(key, time.Now())
+	rows, err := stmt.Query(args...)
+	this.count_error(key, err)
+	return rows, err
 }
 
 func (this *Schema) QueryRow(db *sql.DB, key StatementKey, params ...interface{}) (*sql.Row, error) {
@@ -178,6 +191,7 @@ func (this *Schema) QueryRow(db *sql.DB, key StatementKey, params ...interface{}
 			return nil, err
 		}
 	}
+	defer this.observe(key, time.Now())
 	return stmt.QueryRow(args...), nil
 }
 
@@ -218,3 +232,13 @@ func (this *Schema) statement(key StatementKey) (*Statement, *sql.Stmt, error) {
 	}
 	return &s, stmt, nil
 }
+
+func (this *Schema) observe(key StatementKey, start time.Time) {
+	statement_latency.With(this.Name, strconv.Itoa(int(key))).Observe(time.Since(start).Seconds())
+}
+
+func (this *Schema) count_error(key StatementKey, err error) {
+	if err != nil {
+		statement_errors.With(this.Name, strconv.Itoa(int(key))).Inc()
+	}
+}
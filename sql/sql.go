@@ -4,7 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/logging"
 	"github.com/qorio/omni/version"
 )
 
@@ -14,6 +14,8 @@ const (
 	POSTGRES Platform = Platform("postgres")
 )
 
+var logger = logging.For("sql")
+
 var (
 	ErrNotConnected   = errors.New("not-connected")
 	ErrSchemaMismatch = errors.New("schemas-mismatch")
@@ -78,25 +80,25 @@ func sync_schemas(db *sql.DB, schemas []*Schema, create, update bool) error {
 		check_schema(schema)
 
 		version, hash, err := schema.CurrentVersion(db)
-		glog.Infoln(schema.Name, schema.Version, " -- curent db:", err, version, hash)
+		logger.Info("schema-check", "schema", schema.Name, "version", schema.Version, "db_version", version, "db_hash", hash, "error", err)
 		switch {
 		case err == ErrNotFound:
-			glog.Warningln(schema.Name, schema.Version, " -- is not in db")
+			logger.Warning("schema-not-in-db", "schema", schema.Name, "version", schema.Version)
 			creates = append(creates, schema)
 		case err != nil:
 			return err
 		case version < schema.Version:
-			glog.Warningln(schema.Name, schema.Version, " -- current db version", version, hash,
-				"needs update to", schema.Version)
+			logger.Warning("schema-needs-update", "schema", schema.Name, "version", schema.Version,
+				"db_version", version, "db_hash", hash)
 			updates = append(updates, schema)
 		case version >= schema.Version:
-			glog.Infoln(schema.Name, schema.Version, " -- current db version", version, hash,
-				"is newer than or equal to ", schema.Version, "--> no action.")
+			logger.Info("schema-up-to-date", "schema", schema.Name, "version", schema.Version,
+				"db_version", version, "db_hash", hash)
 		}
 	}
 
 	if len(creates) > 0 {
-		glog.Infoln("Tables to create:", len(creates))
+		logger.Info("schemas-to-create", "count", len(creates))
 
 		if create {
 			for _, s := range creates {
@@ -2,7 +2,7 @@ package tally
 
 import (
 	"github.com/garyburd/redigo/redis"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/health"
 )
 
 type SubscriberSettings struct {
@@ -30,20 +30,35 @@ type tallySubscriberImpl struct {
 func InitSubscriber(settings SubscriberSettings) (impl *tallySubscriberImpl, err error) {
 	subscribe, err := redis.Dial("tcp", settings.RedisUrl)
 	if err != nil {
-		glog.Warningln("error-connect-redis-subscribe", settings, err)
+		logger.Warning("error-connect-redis-subscribe", "redis", settings.RedisUrl, "channel", settings.RedisChannel, "error", err)
 		return
 	}
 	queue, err := redis.Dial("tcp", settings.RedisUrl)
 	if err != nil {
-		glog.Warningln("error-connect-redis-push-queue", settings, err)
+		logger.Warning("error-connect-redis-push-queue", "redis", settings.RedisUrl, "channel", settings.RedisChannel, "error", err)
 		return
 	}
-	return &tallySubscriberImpl{
+	impl = &tallySubscriberImpl{
 		settings:  settings,
 		stop:      make(chan bool),
 		queue:     queue,
 		subscribe: subscribe,
-	}, nil
+	}
+	health.Register(impl.health_check_name(), impl.check, health.Options{})
+	return impl, nil
+}
+
+func (this *tallySubscriberImpl) health_check_name() string {
+	return "tally-subscriber:" + this.settings.RedisUrl + "/" + this.settings.RedisChannel
+}
+
+// The connections are in use by the subscribe and queue loops so they are not pinged;
+// instead the check fails once either connection is broken.
+func (this *tallySubscriberImpl) check() error {
+	if err := this.subscribe.Err(); err != nil {
+		return err
+	}
+	return this.queue.Err()
 }
 
 func (this *tallySubscriberImpl) Channel() <-chan interface{} {
@@ -65,17 +80,17 @@ func (this *tallySubscriberImpl) Queue(queue string, inbound <-chan interface{})
 					// do a read of the length in the hope that at some point the queue starts to drain
 					queueLength, err = redis.Int(this.queue.Do("LLEN", queue))
 					if err != nil {
-						glog.Warningln("error-llen", queue, err, this.settings)
+						logger.Warning("error-llen", "queue", queue, "redis", this.settings.RedisUrl, "error", err)
 					}
 					if queueLength >= this.settings.MaxQueueLength {
-						glog.Warningln("queue-length-exceeds-limit", this.settings.MaxQueueLength, queueLength)
+						logger.Warning("queue-length-exceeds-limit", "queue", queue, "limit", this.settings.MaxQueueLength, "length", queueLength)
 						// drop the message
 						continue
 					}
 				}
 				queueLength, err = redis.Int(this.queue.Do("LPUSH", queue, message))
 				if err != nil {
-					glog.Warningln("error-lpush", queue, err, this.settings)
+					logger.Warning("error-lpush", "queue", queue, "redis", this.settings.RedisUrl, "error", err)
 				}
 			case stop := <-this.stop:
 				if stop {
@@ -90,7 +105,7 @@ func (this *tallySubscriberImpl) Start() {
 	psc := redis.PubSubConn{this.subscribe}
 	err := psc.Subscribe(this.settings.RedisChannel)
 	if err != nil {
-		glog.Warningln("cannot-subscribe-channel", this.settings.RedisChannel, this.settings)
+		logger.Warning("cannot-subscribe-channel", "redis", this.settings.RedisUrl, "channel", this.settings.RedisChannel, "error", err)
 	}
 
 	go func() {
@@ -101,9 +116,9 @@ func (this *tallySubscriberImpl) Start() {
 					this.channel <- message.Data
 				}
 			case redis.Subscription:
-				glog.Infoln("subscription", message.Channel, "kind", message.Kind, "count", message.Count)
+				logger.Info("subscription", "channel", message.Channel, "kind", message.Kind, "count", message.Count)
 			case error:
-				glog.Warningln("error-from-subscribed-channel", message)
+				logger.Warning("error-from-subscribed-channel", "channel", this.settings.RedisChannel, "error", message)
 				return
 			}
 		}
@@ -115,6 +130,7 @@ func (this *tallySubscriberImpl) Stop() {
 }
 
 func (this *tallySubscriberImpl) Close() {
+	health.Unregister(this.health_check_name())
 	this.subscribe.Close()
 	this.queue.Close()
 }
@@ -3,13 +3,22 @@ package tally
 import (
 	"encoding/json"
 	"github.com/garyburd/redigo/redis"
-	"github.com/golang/glog"
+	"github.com/qorio/omni/health"
+	"github.com/qorio/omni/logging"
+	"github.com/qorio/omni/metrics"
 	"math"
 	"regexp"
 	"strconv"
 	"time"
 )
 
+var (
+	published = metrics.NewCounter("tally_events_published_total",
+		"Events published to redis by result: ok, no_subscribers or error.", "result")
+
+	logger = logging.For("tally")
+)
+
 type Settings struct {
 	RedisUrl     string
 	RedisChannel string
@@ -30,7 +39,7 @@ type tallyImpl struct {
 }
 
 func Init(settings Settings) *tallyImpl {
-	return &tallyImpl{
+	impl := &tallyImpl{
 		settings: settings,
 		channel:  make(chan *Event),
 		stop:     make(chan bool),
@@ -50,6 +59,19 @@ func Init(settings Settings) *tallyImpl {
 			},
 		},
 	}
+	health.Register(impl.health_check_name(), impl.ping, health.Options{})
+	return impl
+}
+
+func (this *tallyImpl) health_check_name() string {
+	return "tally:" + this.settings.RedisUrl + "/" + this.settings.RedisChannel
+}
+
+func (this *tallyImpl) ping() error {
+	c := this.pool.Get()
+	defer c.Close()
+	_, err := c.Do("PING")
+	return err
 }
 
 func (this *tallyImpl) Channel() chan<- *Event {
@@ -63,9 +85,13 @@ func (this *tallyImpl) Start() {
 			case message := <-this.channel:
 				count, err := this.publish(message)
 				if err != nil {
-					glog.Warningln("error-publish", err, this)
+					logger.Warning("error-publish", "redis", this.settings.RedisUrl, "channel", this.settings.RedisChannel, "error", err)
+					published.With("error").Inc()
 				} else if count == 0 {
-					glog.Warningln("no-subscribers", this)
+					logger.Warning("no-subscribers", "redis", this.settings.RedisUrl, "channel", this.settings.RedisChannel)
+					published.With("no_subscribers").Inc()
+				} else {
+					published.With("ok").Inc()
 				}
 			case stop := <-this.stop:
 				if stop {
@@ -81,8 +107,9 @@ func (this *tallyImpl) Stop() {
 }
 
 func (this *tallyImpl) Close() {
+	health.Unregister(this.health_check_name())
 	if err := this.pool.Close(); err != nil {
-		glog.Warningln("error-closing-pool", err)
+		logger.Warning("error-closing-pool", "redis", this.settings.RedisUrl, "error", err)
 	}
 }
 